@@ -3,76 +3,216 @@ package l10n
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/jinzhu/gorm"
 	"github.com/qor/qor/utils"
 )
 
 func beforeQuery(scope *gorm.Scope) {
+	if isPseudoLocalized(scope) {
+		// pseudo locale is generated from global records when querying
+		if IsLocalizable(scope) {
+			scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope))), LocaleValue(scope, getConfig(scope).GlobalLocale))
+		}
+		return
+	}
+
 	if IsLocalizable(scope) {
+		config := getConfig(scope)
 		quotedTableName := scope.QuotedTableName()
-		quotedPrimaryKey := scope.Quote(scope.PrimaryKey())
+		quotedPrimaryKeys := quotedPrimaryKeys(scope)
+		quotedLanguageColumn := scope.Quote(LanguageColumn(scope))
 		_, hasDeletedAtColumn := scope.FieldByName("deleted_at")
 
+		mode, err := getMode(scope)
+		if err != nil {
+			scope.Err(err)
+			return
+		}
+
 		locale, isLocale := getQueryLocale(scope)
-		switch mode, _ := scope.DB().Get("l10n:mode"); mode {
-		case "unscoped":
-		case "global":
-			scope.Search.Where(fmt.Sprintf("%v.language_code = ?", quotedTableName), Global)
-		case "locale":
-			scope.Search.Where(fmt.Sprintf("%v.language_code = ?", quotedTableName), locale)
-		case "reverse":
-			if !scope.Search.Unscoped && hasDeletedAtColumn {
-				scope.Search.Where(fmt.Sprintf(
-					"(%v.%v NOT IN (SELECT DISTINCT(%v) FROM %v t2 WHERE t2.language_code = ? AND t2.deleted_at IS NULL) AND %v.language_code = ?)", quotedTableName, quotedPrimaryKey, quotedPrimaryKey, quotedTableName, quotedTableName), locale, Global)
-			} else {
-				scope.Search.Where(fmt.Sprintf("(%v.%v NOT IN (SELECT DISTINCT(%v) FROM %v t2 WHERE t2.language_code = ?) AND %v.language_code = ?)", quotedTableName, quotedPrimaryKey, quotedPrimaryKey, quotedTableName, quotedTableName), locale, Global)
+		switch mode {
+		case ModeUnscoped:
+		case ModeHidden:
+			if !isHideable(scope) {
+				scope.Err(fmt.Errorf("l10n: %v can't be hidden", scope.GetModelStruct().ModelType.Name()))
+				return
 			}
-		case "fallback":
-			fallthrough
-		default:
+			scope.Search.Where(fmt.Sprintf("%v.%v = ? AND %v.l10n_hidden = ?", quotedTableName, quotedLanguageColumn, quotedTableName), LocaleValue(scope, locale), true)
+		case ModeGlobal:
+			scope.Search.Where(fmt.Sprintf("%v.%v = ?", quotedTableName, quotedLanguageColumn), LocaleValue(scope, config.GlobalLocale))
+		case ModeLocale:
+			scope.Search.Where(fmt.Sprintf("%v.%v = ?", quotedTableName, quotedLanguageColumn), LocaleValue(scope, locale))
+		case ModeReverse:
+			scope.Search.Where(reverseCondition(quotedTableName, quotedPrimaryKeys, quotedLanguageColumn, !scope.Search.Unscoped && hasDeletedAtColumn), LocaleValue(scope, locale), LocaleValue(scope, config.GlobalLocale))
+		case ModeStale:
+			if !isRevisionTracked(scope) {
+				scope.Err(fmt.Errorf("l10n: %v doesn't track revisions", scope.GetModelStruct().ModelType.Name()))
+				return
+			}
+			scope.Search.Where(staleCondition(quotedTableName, quotedPrimaryKeys, quotedLanguageColumn, !scope.Search.Unscoped && hasDeletedAtColumn), LocaleValue(scope, locale), LocaleValue(scope, config.GlobalLocale))
+		case ModeFallback:
 			if isLocale {
+				var (
+					conditions      []string
+					values          []interface{}
+					locales         = append([]string{locale}, config.FallbackLocales(locale)...)
+					deletedAtFilter string
+				)
+
 				if !scope.Search.Unscoped && hasDeletedAtColumn {
-					scope.Search.Where(fmt.Sprintf("((%v.%v NOT IN (SELECT DISTINCT(%v) FROM %v t2 WHERE t2.language_code = ? AND t2.deleted_at IS NULL) AND %v.language_code = ?) OR %v.language_code = ?) AND %v.deleted_at IS NULL", quotedTableName, quotedPrimaryKey, quotedPrimaryKey, quotedTableName, quotedTableName, quotedTableName, quotedTableName), locale, Global, locale)
+					deletedAtFilter = " AND t2.deleted_at IS NULL"
+				}
+
+				// use the record from the most specific locale in the fallback chain
+				for idx, fallback := range locales {
+					if idx == 0 {
+						conditions = append(conditions, fmt.Sprintf("(%v.%v = ?)", quotedTableName, quotedLanguageColumn))
+						values = append(values, LocaleValue(scope, fallback))
+					} else {
+						conditions = append(conditions, fmt.Sprintf("(NOT EXISTS (SELECT 1 FROM %v t2 WHERE %v AND t2.%v IN (?)%v) AND %v.%v = ?)", quotedTableName, primaryKeysJoinCondition("t2", quotedTableName, quotedPrimaryKeys), quotedLanguageColumn, deletedAtFilter, quotedTableName, quotedLanguageColumn))
+						values = append(values, localeValues(scope, locales[:idx]), LocaleValue(scope, fallback))
+					}
+				}
+
+				if !scope.Search.Unscoped && hasDeletedAtColumn {
+					scope.Search.Where(fmt.Sprintf("(%v) AND %v.deleted_at IS NULL", strings.Join(conditions, " OR "), quotedTableName), values...)
 				} else {
-					scope.Search.Where(fmt.Sprintf("(%v.%v NOT IN (SELECT DISTINCT(%v) FROM %v t2 WHERE t2.language_code = ?) AND %v.language_code = ?) OR (%v.language_code = ?)", quotedTableName, quotedPrimaryKey, quotedPrimaryKey, quotedTableName, quotedTableName, quotedTableName), locale, Global, locale)
+					scope.Search.Where(strings.Join(conditions, " OR "), values...)
 				}
-				scope.Search.Order(gorm.Expr(fmt.Sprintf("%v.language_code = ? DESC", quotedTableName), locale))
+				scope.Search.Order(gorm.Expr(fmt.Sprintf("%v.%v = ? DESC", quotedTableName, quotedLanguageColumn), LocaleValue(scope, locale)))
 			} else {
-				scope.Search.Where(fmt.Sprintf("%v.language_code = ?", quotedTableName), Global)
+				scope.Search.Where(fmt.Sprintf("%v.%v = ?", quotedTableName, quotedLanguageColumn), LocaleValue(scope, config.GlobalLocale))
+			}
+		}
+
+		// records hidden in the locale are excluded, hidden records in fallback locales hide the record for the locale too
+		if isHideable(scope) && isLocale && (mode == ModeFallback || mode == ModeLocale || mode == ModeStale) {
+			scope.Search.Where(fmt.Sprintf("(%v.l10n_hidden IS NULL OR %v.l10n_hidden = ?)", quotedTableName, quotedTableName), false)
+		}
+	} else if IsTranslatable(scope) {
+		beforeQueryTranslations(scope)
+	}
+}
+
+// reverseCondition condition of global records that haven't been localized, takes locale and global locale as arguments
+func reverseCondition(quotedTableName string, quotedPrimaryKeys []string, quotedLanguageColumn string, excludeDeleted bool) string {
+	var deletedAtFilter string
+	if excludeDeleted {
+		deletedAtFilter = " AND t2.deleted_at IS NULL"
+	}
+	return fmt.Sprintf("(NOT EXISTS (SELECT 1 FROM %v t2 WHERE %v AND t2.%v = ?%v) AND %v.%v = ?)", quotedTableName, primaryKeysJoinCondition("t2", quotedTableName, quotedPrimaryKeys), quotedLanguageColumn, deletedAtFilter, quotedTableName, quotedLanguageColumn)
+}
+
+// staleCondition condition of localized records whose revision is different from the global record's, takes locale and global locale as arguments
+func staleCondition(quotedTableName string, quotedPrimaryKeys []string, quotedLanguageColumn string, excludeDeleted bool) string {
+	var deletedAtFilter string
+	if excludeDeleted {
+		deletedAtFilter = " AND t2.deleted_at IS NULL"
+	}
+	return fmt.Sprintf("%v.%v = ? AND EXISTS (SELECT 1 FROM %v t2 WHERE %v AND t2.%v = ? AND COALESCE(t2.l10n_revision, '') <> COALESCE(%v.l10n_revision, '')%v)", quotedTableName, quotedLanguageColumn, quotedTableName, primaryKeysJoinCondition("t2", quotedTableName, quotedPrimaryKeys), quotedLanguageColumn, quotedTableName, deletedAtFilter)
+}
+
+// primaryKeysJoinCondition condition of records in alias that have the same primary keys with the record in quotedTableName, e.g: t2.id = products.id
+func primaryKeysJoinCondition(alias, quotedTableName string, quotedPrimaryKeys []string) string {
+	var conditions []string
+	for _, quotedPrimaryKey := range quotedPrimaryKeys {
+		conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, quotedPrimaryKey, quotedTableName, quotedPrimaryKey))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func afterQuery(scope *gorm.Scope) {
+	if !scope.HasError() && isPseudoLocalized(scope) {
+		if IsLocalizable(scope) || IsTranslatable(scope) {
+			pseudolocalizeFields(scope)
+		}
+		return
+	}
+
+	if !scope.HasError() && IsLocalizable(scope) {
+		if locale, isLocale := getQueryLocale(scope); isLocale {
+			if mode, _ := getMode(scope); mode == ModeFallback {
+				fillFallbackFields(scope, locale)
 			}
 		}
 	}
+
+	if !scope.HasError() && (IsLocalizable(scope) || IsTranslatable(scope)) {
+		cacheLocalizations(scope)
+	}
 }
 
 func beforeCreate(scope *gorm.Scope) {
 	if IsLocalizable(scope) {
+		if isPseudoLocale(scope) {
+			scope.Err(fmt.Errorf("l10n: pseudo locale %v is read only", PseudoLocale))
+			return
+		}
+
 		if locale, ok := getLocale(scope); ok { // is locale
+			if err := validateLocale(getConfig(scope), locale); err != nil {
+				scope.Err(err)
+				return
+			}
+
 			if isLocaleCreatable(scope) || !scope.PrimaryKeyZero() {
 				setLocale(scope, locale)
+				if isRevisionTracked(scope) {
+					setRevision(scope, globalRevision(scope))
+				}
 			} else {
 				err := fmt.Errorf("the resource %v cannot be created in %v", scope.GetModelStruct().ModelType.Name(), locale)
 				scope.Err(err)
 			}
 		} else {
-			setLocale(scope, Global)
+			setLocale(scope, getConfig(scope).GlobalLocale)
+			if isRevisionTracked(scope) {
+				setRevision(scope, revisionOf(scope))
+			}
 		}
 	}
 }
 
 func beforeUpdate(scope *gorm.Scope) {
 	if IsLocalizable(scope) {
+		if isPseudoLocale(scope) {
+			scope.Err(fmt.Errorf("l10n: pseudo locale %v is read only", PseudoLocale))
+			return
+		}
+
+		mode, err := getMode(scope)
+		if err != nil {
+			scope.Err(err)
+			return
+		}
+
 		locale, isLocale := getLocale(scope)
+		if err := validateLocale(getConfig(scope), locale); err != nil {
+			scope.Err(err)
+			return
+		}
 
-		switch mode, _ := scope.DB().Get("l10n:mode"); mode {
-		case "unscoped":
-		default:
-			scope.Search.Where(fmt.Sprintf("%v.language_code = ?", scope.QuotedTableName()), locale)
+		if mode != ModeUnscoped {
+			scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope))), LocaleValue(scope, locale))
 			setLocale(scope, locale)
 		}
 
 		if isLocale {
 			scope.Search.Omit(syncColumns(scope)...)
+
+			// placeholders of hidden records become localized records once they are saved in the locale
+			if isHideable(scope) {
+				if field, ok := scope.FieldByName("L10nPlaceholder"); ok {
+					scope.SetColumn(field, false)
+				}
+			}
+
+			// localized record is translated from the global record's current revision
+			if mode != ModeUnscoped && isRevisionTracked(scope) {
+				setRevision(scope, globalRevision(scope))
+			}
 		}
 	}
 }
@@ -83,22 +223,30 @@ func afterUpdate(scope *gorm.Scope) {
 			if locale, ok := getLocale(scope); ok {
 				if scope.DB().RowsAffected == 0 && !scope.PrimaryKeyZero() { //is locale and nothing updated
 					var count int
-					var query = fmt.Sprintf("%v.language_code = ? AND %v.%v = ?", scope.QuotedTableName(), scope.QuotedTableName(), scope.PrimaryKey())
+					var condition, primaryValues, err = primaryKeyCondition(scope)
+					if err != nil {
+						scope.Err(err)
+						return
+					}
+
+					var query = fmt.Sprintf("%v.%v = ? AND %v", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope)), condition)
+					var values = append([]interface{}{LocaleValue(scope, locale)}, primaryValues...)
 
 					// if enabled soft delete, delete soft deleted records
 					if scope.HasColumn("DeletedAt") {
-						scope.NewDB().Unscoped().Where("deleted_at is not null").Where(query, locale, scope.PrimaryKeyValue()).Delete(scope.Value)
+						scope.NewDB().Unscoped().Where("deleted_at is not null").Where(query, values...).Delete(scope.Value)
 					}
 
 					// if no localized records exist, localize it
-					if scope.NewDB().Table(scope.TableName()).Where(query, locale, scope.PrimaryKeyValue()).Count(&count); count == 0 {
+					if err := scope.NewDB().Table(scope.TableName()).Where(query, values...).Count(&count).Error; err != nil {
+						scope.Err(err)
+					} else if count == 0 {
 						scope.DB().RowsAffected = scope.DB().Create(scope.Value).RowsAffected
 					}
 				}
-			} else if syncColumns := syncColumns(scope); len(syncColumns) > 0 { // is global
-				if mode, _ := scope.DB().Get("l10n:mode"); mode != "unscoped" {
+			} else if mode, _ := getMode(scope); mode != ModeUnscoped { // is global
+				if syncColumns := syncColumns(scope); len(syncColumns) > 0 {
 					if scope.DB().RowsAffected > 0 {
-						var primaryField = scope.PrimaryField()
 						var syncAttrs = map[string]interface{}{}
 
 						if updateAttrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
@@ -119,14 +267,20 @@ func afterUpdate(scope *gorm.Scope) {
 						}
 
 						if len(syncAttrs) > 0 {
-							db := scope.DB().Model(reflect.New(utils.ModelType(scope.Value)).Interface()).Set("l10n:mode", "unscoped").Where("language_code <> ?", Global)
-							if !primaryField.IsBlank {
-								db = db.Where(fmt.Sprintf("%v = ?", primaryField.DBName), primaryField.Field.Interface())
+							db := scope.DB().Model(reflect.New(utils.ModelType(scope.Value)).Interface()).Set("l10n:mode", ModeUnscoped).Where(fmt.Sprintf("%v <> ?", scope.Quote(LanguageColumn(scope))), LocaleValue(scope, getConfig(scope).GlobalLocale))
+							for _, field := range primaryFields(scope) {
+								if !field.IsBlank {
+									db = db.Where(fmt.Sprintf("%v = ?", scope.Quote(field.DBName)), field.Field.Interface())
+								}
 							}
 							scope.Err(db.UpdateColumns(syncAttrs).Error)
 						}
 					}
 				}
+
+				if isRevisionTracked(scope) && scope.DB().RowsAffected > 0 {
+					refreshGlobalRevision(scope)
+				}
 			}
 		}
 	}
@@ -134,14 +288,24 @@ func afterUpdate(scope *gorm.Scope) {
 
 func beforeDelete(scope *gorm.Scope) {
 	if IsLocalizable(scope) {
+		if isPseudoLocale(scope) {
+			scope.Err(fmt.Errorf("l10n: pseudo locale %v is read only", PseudoLocale))
+			return
+		}
+
 		if locale, ok := getQueryLocale(scope); ok { // is locale
-			scope.Search.Where(fmt.Sprintf("%v.language_code = ?", scope.QuotedTableName()), locale)
+			scope.Search.Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope))), LocaleValue(scope, locale))
 		}
 	}
 }
 
-// RegisterCallbacks register callback into GORM DB
-func RegisterCallbacks(db *gorm.DB) {
+// RegisterCallbacks register callback into GORM DB, with optional configuration
+func RegisterCallbacks(db *gorm.DB, config ...*Config) {
+	// attach the configuration to the DB, DBs derived from it later will use the configuration, DBs without configuration use package variables
+	if len(config) > 0 && config[0] != nil {
+		attachConfig(db, config[0])
+	}
+
 	callback := db.Callback()
 
 	if callback.Create().Get("l10n:before_create") == nil {
@@ -159,10 +323,27 @@ func RegisterCallbacks(db *gorm.DB) {
 		callback.Delete().Before("gorm:before_delete").Register("l10n:before_delete", beforeDelete)
 	}
 
+	// translations are saved before the transaction begins, as saving the record itself will be skipped
+	if callback.Create().Get("l10n:save_translations") == nil {
+		callback.Create().Before("gorm:begin_transaction").Register("l10n:save_translations", beforeSaveTranslations)
+	}
+	if callback.Update().Get("l10n:save_translations") == nil {
+		callback.Update().Before("gorm:begin_transaction").Register("l10n:save_translations", beforeSaveTranslations)
+	}
+	if callback.Delete().Get("l10n:delete_translations") == nil {
+		callback.Delete().Before("gorm:begin_transaction").Register("l10n:delete_translations", beforeDeleteTranslations)
+	}
+	if callback.Delete().Get("l10n:delete_global_translations") == nil {
+		callback.Delete().After("gorm:delete").Register("l10n:delete_global_translations", afterDeleteTranslations)
+	}
+
 	if callback.RowQuery().Get("l10n:before_query") == nil {
 		callback.RowQuery().Before("gorm:row_query").Register("l10n:before_query", beforeQuery)
 	}
 	if callback.Query().Get("l10n:before_query") == nil {
 		callback.Query().Before("gorm:query").Register("l10n:before_query", beforeQuery)
 	}
+	if callback.Query().Get("l10n:after_query") == nil {
+		callback.Query().After("gorm:after_query").Register("l10n:after_query", afterQuery)
+	}
 }
@@ -0,0 +1,100 @@
+// Command l10n provides tools to manage localized data, e.g: report localization coverage
+//
+//	l10n coverage -dialect mysql -dsn "user:password@/db" -tables products,brands -format json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/qor/l10n"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "coverage":
+		if err := coverage(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: l10n coverage -dialect <dialect> -dsn <dsn> -tables <tables> [-primary-key id] [-language-column language_code] [-locales <locales>] [-global en-US] [-format table|json]")
+	os.Exit(2)
+}
+
+func coverage(args []string) error {
+	var (
+		flags      = flag.NewFlagSet("coverage", flag.ExitOnError)
+		dialect    = flags.String("dialect", "mysql", "database dialect, mysql, postgres or sqlite3")
+		dsn        = flags.String("dsn", "", "database source name")
+		tables     = flags.String("tables", "", "localizable tables, separated by comma")
+		primaryKey = flags.String("primary-key", "id", "primary key columns besides the language column, separated by comma")
+		language   = flags.String("language-column", "language_code", "column of locales")
+		locales    = flags.String("locales", "", "locales to report, separated by comma, default is all locales that have localized records")
+		global     = flags.String("global", l10n.Global, "global locale")
+		format     = flags.String("format", "table", "output format, table or json")
+	)
+	flags.Parse(args)
+
+	if *dsn == "" || *tables == "" {
+		usage()
+	}
+
+	db, err := gorm.Open(*dialect, *dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	l10n.RegisterCallbacks(db, &l10n.Config{GlobalLocale: *global, LanguageColumn: *language})
+
+	var reports []l10n.CoverageReport
+	for _, table := range splitValues(*tables) {
+		tableReports, err := l10n.TableCoverage(db, table, *primaryKey, splitValues(*locales)...)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, tableReports...)
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case "table":
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "TABLE\tLOCALE\tGLOBAL\tLOCALIZED\tSTALE\tMISSING\tHIDDEN\tCOVERAGE")
+		for _, report := range reports {
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%.1f%%\n", report.Table, report.Locale, report.Global, report.Localized, report.Stale, report.Missing, report.Hidden, report.Percentage())
+		}
+		return writer.Flush()
+	}
+	return fmt.Errorf("unknown format %v", *format)
+}
+
+func splitValues(str string) (values []string) {
+	for _, value := range strings.Split(str, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return
+}
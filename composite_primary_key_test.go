@@ -0,0 +1,141 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestCompositePrimaryKeys(t *testing.T) {
+	db := dbGlobal.New()
+	l10n.RegisterCallbacks(db, &l10n.Config{Fallbacks: map[string][]string{"ja": {"zh"}}})
+	dbZH := db.Set("l10n:locale", "zh")
+	dbJA := db.Set("l10n:locale", "ja")
+
+	// records in the same shop share the same first primary key
+	skus := []SKU{
+		{ShopID: 1, Code: "A", Name: "sku a", Description: "sku a description", Stock: 1},
+		{ShopID: 1, Code: "B", Name: "sku b", Description: "sku b description", Stock: 2},
+	}
+	for idx := range skus {
+		checkHasErr(t, db.Create(&skus[idx]).Error)
+	}
+
+	skuZH := SKU{ShopID: 1, Code: "A", Name: "商品 A"}
+	checkHasErr(t, dbZH.Save(&skuZH).Error)
+
+	var reversed []SKU
+	dbZH.Set("l10n:mode", "reverse").Where("shop_id = ?", 1).Find(&reversed)
+	if len(reversed) != 1 || reversed[0].Code != "B" {
+		t.Errorf("only sku b should be unlocalized, but got %#v", reversed)
+	}
+
+	var localized []SKU
+	dbZH.Set("l10n:mode", "locale").Where("shop_id = ?", 1).Find(&localized)
+	if len(localized) != 1 || localized[0].Code != "A" || localized[0].Name != "商品 A" {
+		t.Errorf("only sku a should be localized, but got %#v", localized)
+	}
+
+	var results []SKU
+	dbJA.Where("shop_id = ?", 1).Order("code").Find(&results)
+	if len(results) != 2 || results[0].LanguageCode != "zh" || results[0].Name != "商品 A" || results[1].LanguageCode != l10n.Global || results[1].Name != "sku b" {
+		t.Errorf("should fall back to each record's own localization, but got %#v", results)
+	}
+
+	if results[0].Description != "sku a description" {
+		t.Errorf("fallback fields should be filled from the same record, but got %#v", results[0])
+	}
+
+	// update a record that hasn't been localized in the locale
+	skuZH2 := SKU{ShopID: 1, Code: "B", Name: "商品 B"}
+	checkHasErr(t, dbZH.Model(&skuZH2).Updates(map[string]interface{}{"name": "商品 B"}).Error)
+
+	var count int
+	db.Set("l10n:mode", "unscoped").Model(&SKU{}).Where("shop_id = ?", 1).Count(&count)
+	if count != 4 {
+		t.Errorf("should localize sku b without touching sku a, but got %v records", count)
+	}
+
+	var sku2 SKU
+	dbZH.Set("l10n:mode", "locale").Where("shop_id = ? AND code = ?", 1, "B").First(&sku2)
+	if sku2.Name != "商品 B" {
+		t.Errorf("should localize sku b, but got %#v", sku2)
+	}
+
+	// sync fields are synced to localized records of the same record only
+	skus[0].Stock = 10
+	checkHasErr(t, db.Save(&skus[0]).Error)
+
+	var stocks []SKU
+	db.Set("l10n:mode", "unscoped").Where("shop_id = ?", 1).Order("code").Find(&stocks)
+	for _, sku := range stocks {
+		if (sku.Code == "A") != (sku.Stock == 10) {
+			t.Errorf("sync fields should be synced with all primary keys, but got %#v", sku)
+		}
+	}
+
+	reports, err := l10n.Coverage(dbZH, &SKU{})
+	checkHasErr(t, err)
+	if len(reports) != 1 || reports[0].Global != 2 || reports[0].Localized != 2 || reports[0].Missing != 0 {
+		t.Errorf("should report coverage with composite primary keys, but got %#v", reports)
+	}
+
+	tableReports, err := l10n.TableCoverage(db, "skus", "shop_id,code", "ja")
+	checkHasErr(t, err)
+	if len(tableReports) != 1 || tableReports[0].Missing != 2 {
+		t.Errorf("should report coverage of table with composite primary keys, but got %#v", tableReports)
+	}
+
+	statuses, err := l10n.GetLocalizationStatuses(db, &skus[1], []string{"zh", "ja"})
+	checkHasErr(t, err)
+	if len(statuses) < 2 || statuses[0].State != l10n.LocalizationLocalized || statuses[1].State != l10n.LocalizationMissing {
+		t.Errorf("should get localization statuses with composite primary keys, but got %#v", statuses)
+	}
+
+	unlocalized, err := l10n.Unlocalize(db, &skus[0], "zh")
+	checkHasErr(t, err)
+	if len(unlocalized) != 1 || unlocalized[0].Status != l10n.LocalizeDeleted {
+		t.Errorf("should unlocalize record, but got %#v", unlocalized)
+	}
+
+	if !dbZH.Set("l10n:mode", "locale").Where("shop_id = ? AND code = ?", 1, "A").First(&SKU{}).RecordNotFound() {
+		t.Errorf("localized sku a should be deleted")
+	}
+
+	if dbZH.Set("l10n:mode", "locale").Where("shop_id = ? AND code = ?", 1, "B").First(&SKU{}).RecordNotFound() {
+		t.Errorf("localized sku b should not be deleted")
+	}
+
+	// records with blank primary keys can't be localized
+	if err := dbZH.Model(&SKU{ShopID: 1}).Updates(map[string]interface{}{"name": "blank code"}).Error; err == nil {
+		t.Errorf("should return error when updating localized record with blank primary keys")
+	}
+
+	// zero is a valid value of primary keys that don't auto increment, gorm doesn't insert blank primary keys, so insert them with SQL
+	sku0 := SKU{ShopID: 0, Code: "A"}
+	for _, locale := range []string{l10n.Global, "zh"} {
+		checkHasErr(t, db.Exec("INSERT INTO skus (shop_id, code, name, language_code) VALUES (?, ?, ?, ?)", sku0.ShopID, sku0.Code, "sku 0", locale).Error)
+	}
+
+	unlocalized, err = l10n.Unlocalize(db, &sku0, "zh")
+	checkHasErr(t, err)
+	if len(unlocalized) != 1 || unlocalized[0].Status != l10n.LocalizeDeleted {
+		t.Errorf("should unlocalize record whose primary key is zero, but got %#v", unlocalized)
+	}
+
+	if !dbZH.Set("l10n:mode", "locale").Where("shop_id = ? AND code = ?", 0, "A").First(&SKU{}).RecordNotFound() {
+		t.Errorf("localized record whose primary key is zero should be deleted")
+	}
+
+	condition, values, err := l10n.ParsePrimaryKeys(db.NewScope(&SKU{}), "1,A", "1,B")
+	checkHasErr(t, err)
+	var found []SKU
+	db.Set("l10n:mode", "global").Where(condition, values...).Find(&found)
+	if len(found) != 2 {
+		t.Errorf("should find records with composite primary keys, but got %#v", found)
+	}
+
+	if _, _, err := l10n.ParsePrimaryKeys(db.NewScope(&SKU{}), "1"); err == nil {
+		t.Errorf("should return error for incomplete composite primary keys")
+	}
+}
@@ -0,0 +1,129 @@
+package l10n
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+// Config l10n configuration of a DB, attach it to the DB with `RegisterCallbacks`, blank options are filled with package variables when it is attached, package variables changed later don't affect it, e.g:
+//
+//	l10n.RegisterCallbacks(db, &l10n.Config{GlobalLocale: "zh-CN", SupportedLocales: []string{"zh-CN", "en-US"}})
+type Config struct {
+	// GlobalLocale locale of global records, default is `Global`
+	GlobalLocale string
+	// LanguageColumn column name of locales in translation tables and tables reported by `TableCoverage`, default is "language_code". Localizable models use the column of their `LanguageCode` field, change it with gorm's `column` tag
+	LanguageColumn string
+	// SupportedLocales locales that records could be saved in, default is codes of `Locales` of the configuration, no limitation if both are blank
+	SupportedLocales []string
+	// Locales registered locales, used to canonicalize locales and find parent locales, default is `Locales`, it is copied when the configuration is attached, so each DB could have its own registry
+	Locales LocaleRegistry
+	// Fallbacks fallback chains of locales, default is `Fallbacks`
+	Fallbacks map[string][]string
+	// FallbackToParentLocales fall back to parent locales for locales that haven't configured fallbacks, enabled if it or `FallbackToParentLocales` is true
+	FallbackToParentLocales bool
+	// GlobalAdminRoleName role name of users who could edit sync fields in the global locale in QOR Admin, default is "global_admin"
+	GlobalAdminRoleName string
+	// AdminRoleName role name of users who could edit the current locale in QOR Admin, default is "locale_admin"
+	AdminRoleName string
+	// ReaderRoleName role name of users who could view the current locale in QOR Admin, default is "locale_reader"
+	ReaderRoleName string
+}
+
+const configKey = "l10n:config"
+
+// GetConfig return l10n configuration of the DB, return the default configuration made from package variables if no configuration is attached
+func GetConfig(db *gorm.DB) *Config {
+	if db != nil {
+		if value, ok := db.Get(configKey); ok {
+			if config, ok := value.(*Config); ok && config != nil {
+				// attached configurations have been resolved by `RegisterCallbacks`
+				c := *config
+				return &c
+			}
+		}
+	}
+	return resolveConfig(Config{})
+}
+
+// resolveConfig return a copy of config with blank options filled with package variables
+func resolveConfig(config Config) *Config {
+	if config.GlobalLocale == "" {
+		config.GlobalLocale = Global
+	}
+
+	if config.LanguageColumn == "" {
+		config.LanguageColumn = "language_code"
+	}
+
+	if config.Locales == nil {
+		config.Locales = Locales
+	}
+
+	if config.SupportedLocales == nil {
+		if codes := config.Locales.Codes(); len(codes) > 0 {
+			config.SupportedLocales = codes
+		}
+	}
+
+	if config.Fallbacks == nil {
+		config.Fallbacks = Fallbacks
+	}
+
+	config.FallbackToParentLocales = config.FallbackToParentLocales || FallbackToParentLocales
+
+	if config.GlobalAdminRoleName == "" {
+		config.GlobalAdminRoleName = "global_admin"
+	}
+
+	if config.AdminRoleName == "" {
+		config.AdminRoleName = "locale_admin"
+	}
+
+	if config.ReaderRoleName == "" {
+		config.ReaderRoleName = "locale_reader"
+	}
+	return &config
+}
+
+// attachConfig attach a resolved copy of config to the DB, so package variables and the passed config changed later don't affect the DB
+func attachConfig(db *gorm.DB, config *Config) {
+	resolved := resolveConfig(*config)
+	resolved.SupportedLocales = append([]string(nil), resolved.SupportedLocales...)
+	resolved.Locales = resolved.Locales.clone()
+
+	fallbacks := map[string][]string{}
+	for locale, locales := range resolved.Fallbacks {
+		fallbacks[locale] = append([]string(nil), locales...)
+	}
+	resolved.Fallbacks = fallbacks
+
+	db.InstantSet(configKey, resolved)
+}
+
+func getConfig(scope *gorm.Scope) *Config {
+	return GetConfig(scope.DB())
+}
+
+// IsGlobal return locale is the global locale or not
+func (config *Config) IsGlobal(locale string) bool {
+	return locale == config.GlobalLocale
+}
+
+// IsSupported return records could be saved in locale or not, the global locale is always supported
+func (config *Config) IsSupported(locale string) bool {
+	if locale == config.GlobalLocale || len(config.SupportedLocales) == 0 {
+		return true
+	}
+	return includeLocale(config.SupportedLocales, locale)
+}
+
+// querySettings l10n settings that change queries, they are cleared by `newDB`
+var querySettings = []string{"l10n:locale", "l10n:mode", "l10n:localize_to", "l10n:localize_options", "l10n:localization_cache", "l10n:translator"}
+
+// newDB return a new DB without search conditions and l10n settings that change queries, so queries run by l10n are not affected by the caller's conditions, modes or locales. gorm's `New` keeps settings, so they are cleared one by one, other settings like the l10n configuration and transactions are kept
+func newDB(db *gorm.DB) *gorm.DB {
+	clone := db.New()
+	for _, key := range querySettings {
+		clone = clone.Set(key, nil)
+	}
+	return clone
+}
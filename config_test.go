@@ -0,0 +1,118 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestConfig(t *testing.T) {
+	db := dbGlobal.New()
+	l10n.RegisterCallbacks(db, &l10n.Config{
+		GlobalLocale:     "zh-CN",
+		SupportedLocales: []string{"zh-CN", "zh-TW", "zh-HK", "en-US"},
+		Fallbacks:        map[string][]string{"zh-HK": {"zh-TW"}},
+	})
+
+	if config := l10n.GetConfig(dbGlobal); config.GlobalLocale != l10n.Global || config.AdminRoleName != "locale_admin" {
+		t.Errorf("should use default configuration for DBs without configuration, but got %#v", config)
+	}
+
+	if config := l10n.GetConfig(db.Set("l10n:locale", "zh-TW")); config.GlobalLocale != "zh-CN" || config.ReaderRoleName != "locale_reader" {
+		t.Errorf("derived DBs should use the attached configuration, but got %#v", config)
+	}
+
+	product := Product{Code: "Config", Name: "全球"}
+	db.Create(&product)
+	if product.LanguageCode != "zh-CN" {
+		t.Errorf("global record should be saved in configured global locale, but got %v", product.LanguageCode)
+	}
+
+	if !dbGlobal.Set("l10n:mode", "global").First(&Product{}, product.ID).RecordNotFound() {
+		t.Errorf("DBs with different global locales should not share global records")
+	}
+
+	product.Name = "台灣"
+	db.Set("l10n:locale", "zh-TW").Save(&product)
+
+	var productHK Product
+	db.Set("l10n:locale", "zh-HK").First(&productHK, product.ID)
+	if productHK.LanguageCode != "zh-TW" || productHK.Name != "台灣" {
+		t.Errorf("should fall back with configured fallbacks, but got %#v", productHK)
+	}
+
+	var productUS Product
+	db.Set("l10n:locale", "en-US").First(&productUS, product.ID)
+	if productUS.LanguageCode != "zh-CN" {
+		t.Errorf("should fall back to configured global locale, but got %#v", productUS)
+	}
+
+	if err := db.Set("l10n:locale", "ja-JP").Save(&product).Error; err == nil {
+		t.Errorf("should not save record in unsupported locale")
+	}
+
+	if err := dbGlobal.Set("l10n:locale", "ja-JP").Save(&Product{ID: product.ID, Code: "Config"}).Error; err != nil {
+		t.Errorf("DBs without configuration should not be limited, but got %v", err)
+	}
+}
+
+func TestConfigResolvedWhenRegistered(t *testing.T) {
+	config := &l10n.Config{GlobalLocale: "zh-CN", Fallbacks: map[string][]string{"zh-HK": {"zh-TW"}}}
+	db := dbGlobal.New()
+	l10n.RegisterCallbacks(db, config)
+
+	// changes after registering don't affect the DB
+	config.GlobalLocale = "ja-JP"
+	config.Fallbacks["zh-HK"] = []string{"ja-JP"}
+	l10n.Fallbacks["zh-MO"] = []string{"zh-HK"}
+	defer delete(l10n.Fallbacks, "zh-MO")
+
+	if config := l10n.GetConfig(db); config.GlobalLocale != "zh-CN" || len(config.Fallbacks) != 1 || config.Fallbacks["zh-HK"][0] != "zh-TW" {
+		t.Errorf("configuration should be resolved when registered, but got %#v", config)
+	}
+
+	label := l10n.LocalizedString{"zh-CN": "尺码", "zh-TW": "尺寸", l10n.Global: "Size"}
+	if value := label.Resolve(db.Set("l10n:locale", "zh-HK")); value != "尺寸" {
+		t.Errorf("should fall back with DB's configuration, but got %v", value)
+	}
+
+	if value := label.Resolve(db); value != "尺码" {
+		t.Errorf("should use DB's global locale, but got %v", value)
+	}
+
+	if value := label.Resolve(db.Set("l10n:locale", "ja-JP")); value != "尺码" {
+		t.Errorf("should fall back to DB's global locale, but got %v", value)
+	}
+}
+
+func TestConfigLocales(t *testing.T) {
+	registry := l10n.LocaleRegistry{}
+	registry.Register(l10n.LocaleInfo{Code: "zh-CN"}, l10n.LocaleInfo{Code: "zh-HK", Parent: "zh-CN"})
+
+	db := dbGlobal.New()
+	l10n.RegisterCallbacks(db, &l10n.Config{Locales: registry, FallbackToParentLocales: true})
+
+	// locales registered later don't affect the DB
+	registry.Register(l10n.LocaleInfo{Code: "ja-JP"})
+	l10n.Locales.Register(l10n.LocaleInfo{Code: "fr-FR"})
+	defer func() { l10n.Locales = l10n.LocaleRegistry{} }()
+
+	config := l10n.GetConfig(db)
+	if len(config.SupportedLocales) != 2 || len(config.Locales) != 2 {
+		t.Errorf("should use the registry of the configuration, but got %#v", config)
+	}
+
+	if locales := config.FallbackLocales("zh-HK"); len(locales) != 2 || locales[0] != "zh-CN" {
+		t.Errorf("should fall back to parents in the registry of the configuration, but got %v", locales)
+	}
+
+	product := Product{Code: "ConfigLocales", Name: "global"}
+	db.Create(&product)
+	if err := db.Set("l10n:locale", "zh_cn").Save(&product).Error; err != nil {
+		t.Errorf("should save record in registered locale, but got %v", err)
+	}
+
+	if err := db.Set("l10n:locale", "ja-JP").Save(&product).Error; err == nil {
+		t.Errorf("should not save record in locales registered after attaching the configuration")
+	}
+}
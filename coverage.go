@@ -0,0 +1,178 @@
+package l10n
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// CoverageReport localization coverage of a table in a locale
+type CoverageReport struct {
+	Table  string
+	Locale string
+	// Global count of global records
+	Global int
+	// Localized count of global records that have been localized, hidden records are excluded
+	Localized int
+	// Stale count of localized records whose global records changed after they are localized, only for models that track revisions
+	Stale int
+	// Missing count of global records that haven't been localized
+	Missing int
+	// Hidden count of records hidden in the locale, only for hideable models
+	Hidden int
+}
+
+// Percentage percentage of localized records in records that are not hidden
+func (report CoverageReport) Percentage() float64 {
+	if report.Global-report.Hidden <= 0 {
+		return 100
+	}
+	return float64(report.Localized) * 100 / float64(report.Global-report.Hidden)
+}
+
+// Coverage report localization coverage of models for each locale that has localized records, if DB's locale is set with `l10n:locale`, only report coverage of the locale
+func Coverage(db *gorm.DB, models ...interface{}) (reports []CoverageReport, err error) {
+	var locales []string
+	if locale, ok := db.Get("l10n:locale"); ok {
+		if locale, ok := locale.(string); ok && locale != "" && !GetConfig(db).IsGlobal(locale) {
+			locales = []string{locale}
+		}
+	}
+
+	for _, model := range models {
+		var (
+			scope        = db.NewScope(model)
+			modelReports []CoverageReport
+			primaryKeys  []string
+		)
+
+		for _, field := range scope.GetModelStruct().PrimaryFields {
+			if field.Name != "LanguageCode" {
+				primaryKeys = append(primaryKeys, field.DBName)
+			}
+		}
+
+		if IsTranslatable(scope) {
+			modelReports, err = translationCoverage(db, scope, locales...)
+		} else if IsLocalizable(scope) && len(primaryKeys) > 0 {
+			modelReports, err = tableCoverage(db, scope.TableName(), primaryKeys, LanguageColumn(scope), languageType(scope), locales...)
+		} else {
+			err = fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+		}
+
+		if err != nil {
+			return
+		}
+		reports = append(reports, modelReports...)
+	}
+	return
+}
+
+// TableCoverage report localization coverage of a localizable table for locales, could be used without models, e.g: from command line. Records are matched with primary key columns besides the configured language column, columns of composite primary keys are separated by comma, e.g: "shop_id,code", if no locales given, report coverage of each locale that has localized records
+func TableCoverage(db *gorm.DB, table string, primaryKey string, locales ...string) (reports []CoverageReport, err error) {
+	var primaryKeys []string
+	for _, column := range strings.Split(primaryKey, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	return tableCoverage(db, table, primaryKeys, GetConfig(db).LanguageColumn, nil, locales...)
+}
+
+// tableCoverage report localization coverage of table, locales are converted to languageType in conditions if it is a custom type
+func tableCoverage(db *gorm.DB, table string, primaryKeys []string, languageColumn string, languageType reflect.Type, locales ...string) (reports []CoverageReport, err error) {
+	db = newDB(db)
+
+	var quotedPrimaryKeys []string
+	for _, primaryKey := range primaryKeys {
+		quotedPrimaryKeys = append(quotedPrimaryKeys, db.Dialect().Quote(primaryKey))
+	}
+
+	var (
+		globalLocale         = GetConfig(db).GlobalLocale
+		quotedTableName      = db.Dialect().Quote(table)
+		quotedLanguageColumn = db.Dialect().Quote(languageColumn)
+		hasDeletedAt         = db.Dialect().HasColumn(table, "deleted_at")
+		hasRevision          = db.Dialect().HasColumn(table, "l10n_revision")
+		hasHidden            = db.Dialect().HasColumn(table, "l10n_hidden")
+		deletedAtFilter      string
+	)
+
+	if hasDeletedAt {
+		deletedAtFilter = fmt.Sprintf(" AND %v.deleted_at IS NULL", quotedTableName)
+	}
+
+	if len(locales) == 0 {
+		if locales, err = pluckLocales(db.Table(table).Where(fmt.Sprintf("%v <> ?", quotedLanguageColumn)+deletedAtFilter, convertLocale(languageType, globalLocale)), languageType, quotedLanguageColumn); err != nil {
+			return
+		}
+		sort.Strings(locales)
+	}
+
+	var global int
+	if err = db.Table(table).Where(fmt.Sprintf("%v = ?", quotedLanguageColumn)+deletedAtFilter, convertLocale(languageType, globalLocale)).Count(&global).Error; err != nil {
+		return
+	}
+
+	for _, locale := range locales {
+		report := CoverageReport{Table: table, Locale: locale, Global: global}
+
+		// use the same condition with reverse mode
+		if err = db.Table(table).Where(reverseCondition(quotedTableName, quotedPrimaryKeys, quotedLanguageColumn, hasDeletedAt)+deletedAtFilter, convertLocale(languageType, locale), convertLocale(languageType, globalLocale)).Count(&report.Missing).Error; err != nil {
+			return
+		}
+
+		// hidden records have been localized as hidden, they are neither localized nor missing
+		if hasHidden {
+			if err = db.Table(table).Where(fmt.Sprintf("%v.%v = ? AND %v.l10n_hidden = ?", quotedTableName, quotedLanguageColumn, quotedTableName)+deletedAtFilter, convertLocale(languageType, locale), true).Count(&report.Hidden).Error; err != nil {
+				return
+			}
+		}
+		report.Localized = report.Global - report.Missing - report.Hidden
+
+		if hasRevision {
+			staleDB := db.Table(table).Where(staleCondition(quotedTableName, quotedPrimaryKeys, quotedLanguageColumn, hasDeletedAt)+deletedAtFilter, convertLocale(languageType, locale), convertLocale(languageType, globalLocale))
+			if hasHidden {
+				staleDB = staleDB.Where(fmt.Sprintf("(%v.l10n_hidden IS NULL OR %v.l10n_hidden = ?)", quotedTableName, quotedTableName), false)
+			}
+			if err = staleDB.Count(&report.Stale).Error; err != nil {
+				return
+			}
+		}
+		reports = append(reports, report)
+	}
+	return
+}
+
+// translationCoverage report localization coverage of translatable models, global records are saved in the model's table, and their translations are saved in the translation table
+func translationCoverage(db *gorm.DB, scope *gorm.Scope, locales ...string) (reports []CoverageReport, err error) {
+	var (
+		global           int
+		translationTable = TranslationTableName(scope)
+		modelDB          = WithMode(newDB(db), ModeUnscoped).Model(scope.Value)
+	)
+
+	if len(locales) == 0 {
+		if err = newDB(db).Table(translationTable).Pluck(fmt.Sprintf("DISTINCT %v", scope.Quote(LanguageColumn(scope))), &locales).Error; err != nil {
+			return
+		}
+		sort.Strings(locales)
+	}
+
+	if err = modelDB.Count(&global).Error; err != nil {
+		return
+	}
+
+	for _, locale := range locales {
+		report := CoverageReport{Table: scope.TableName(), Locale: locale, Global: global}
+		if err = WithMode(WithLocale(newDB(db), locale), ModeReverse).Model(scope.Value).Count(&report.Missing).Error; err != nil {
+			return
+		}
+		report.Localized = report.Global - report.Missing
+		reports = append(reports, report)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestCoverage(t *testing.T) {
+	var posts []Post
+	for idx := 0; idx < 4; idx++ {
+		post := Post{Code: "Coverage", Title: "title"}
+		dbGlobal.Create(&post)
+		posts = append(posts, post)
+	}
+
+	dbGlobal.Set("l10n:locale", "ja").Create(&posts[0])
+	dbGlobal.Set("l10n:locale", "ja").Create(&posts[1])
+	dbGlobal.Model(&posts[1]).Update("title", "new title")
+
+	var global, localized, stale int
+	dbGlobal.Set("l10n:mode", "global").Model(&Post{}).Count(&global)
+	dbGlobal.Set("l10n:locale", "ja").Set("l10n:mode", "locale").Model(&Post{}).Count(&localized)
+	dbGlobal.Set("l10n:locale", "ja").Set("l10n:mode", "stale").Model(&Post{}).Count(&stale)
+
+	reports, err := l10n.Coverage(dbGlobal.Set("l10n:locale", "ja"), &Post{}, &Article{})
+	checkHasErr(t, err)
+	if len(reports) != 2 {
+		t.Fatalf("should report coverage for each model, but got %#v", reports)
+	}
+
+	if report := reports[0]; report.Table != "posts" || report.Locale != "ja" || report.Global != global || report.Localized != localized || report.Stale != stale || report.Missing != global-localized || stale == 0 {
+		t.Errorf("should report coverage of posts, but got %#v", report)
+	}
+
+	tableReports, err := l10n.TableCoverage(dbGlobal, "posts", "id")
+	checkHasErr(t, err)
+	var found bool
+	for _, report := range tableReports {
+		if report.Locale == "ja" {
+			found = report == reports[0]
+		}
+	}
+
+	if !found {
+		t.Errorf("should report coverage of each locale for tables, but got %#v", tableReports)
+	}
+}
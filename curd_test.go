@@ -189,3 +189,28 @@ func TestManyToManyRelations(t *testing.T) {
 	product := Product{Code: "Delete", Name: "global", Tags: []Tag{{Name: "tag1"}, {Name: "tag2"}}}
 	dbGlobal.Save(&product)
 }
+
+func TestQueryWithTypedMode(t *testing.T) {
+	product := Product{Code: "TypedMode", Name: "global"}
+	dbGlobal.Create(&product)
+	dbCN.Create(&product)
+
+	var productCN Product
+	if l10n.WithMode(l10n.WithLocale(dbGlobal, "zh"), l10n.ModeLocale).First(&productCN, product.ID); productCN.LanguageCode != "zh" {
+		t.Error("Should find localized zh product with locale mode")
+	}
+
+	var productGlobal Product
+	if l10n.WithMode(dbCN, l10n.ModeGlobal).First(&productGlobal, product.ID); productGlobal.LanguageCode != l10n.Global {
+		t.Error("Should find global product with global mode")
+	}
+
+	var productUnknown Product
+	if err := dbCN.Set("l10n:mode", "revers").First(&productUnknown, product.ID).Error; err == nil {
+		t.Error("Should return error with unknown mode")
+	}
+
+	if err := l10n.WithMode(dbCN, "unknown").Save(&product).Error; err == nil {
+		t.Error("Should return error when saving with unknown mode")
+	}
+}
@@ -0,0 +1,286 @@
+package l10n
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TranslationUnit a translatable field of a record, used to exchange translations with other tools
+type TranslationUnit struct {
+	// Key stable key of the unit, made from table name, primary key and column, e.g: products/1/name
+	Key    string
+	Source string
+	Target string
+}
+
+// ImportResult result of importing translations
+type ImportResult struct {
+	// Imported keys of imported units
+	Imported []string
+	// Untranslated keys of units that haven't been translated
+	Untranslated []string
+	// Fuzzy keys of units that are marked as fuzzy, they won't be imported
+	Fuzzy  []string
+	Errors []ImportError
+}
+
+// ImportError error of importing a unit
+type ImportError struct {
+	Key string
+	Err error
+}
+
+func (err ImportError) Error() string {
+	return fmt.Sprintf("%v: %v", err.Key, err.Err)
+}
+
+func translationUnitKey(table string, primaryKey string, column string) string {
+	return strings.Join([]string{url.PathEscape(table), url.PathEscape(primaryKey), url.PathEscape(column)}, "/")
+}
+
+func parseTranslationUnitKey(key string) (table string, primaryKey string, column string, err error) {
+	values := strings.Split(key, "/")
+	if len(values) != 3 {
+		return "", "", "", fmt.Errorf("invalid unit key %v", key)
+	}
+
+	for idx, value := range values {
+		if values[idx], err = url.PathUnescape(value); err != nil {
+			return
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// exchangeFields return string fields that could be translated for localizable or translatable models
+func exchangeFields(scope *gorm.Scope) (fields []*gorm.StructField) {
+	var candidates []*gorm.StructField
+	if IsTranslatable(scope) {
+		candidates = translateFields(scope)
+	} else if IsLocalizable(scope) {
+		candidates = translatableFields(scope)
+	}
+
+	for _, field := range candidates {
+		if field.Struct.Type.Kind() == reflect.String {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// recordPrimaryKey return the record's primary key as a string, values of composite primary keys are separated by comma
+func recordPrimaryKey(scope *gorm.Scope) string {
+	var values []interface{}
+	for _, field := range primaryFields(scope) {
+		values = append(values, field.Field.Interface())
+	}
+	return primaryKeyString(values)
+}
+
+// primaryKeyHeader return column name of primary keys used by exchanges, columns of composite primary keys are separated by comma
+func primaryKeyHeader(scope *gorm.Scope) string {
+	var columns []string
+	for _, field := range primaryFields(scope) {
+		columns = append(columns, field.DBName)
+	}
+	return strings.Join(columns, ",")
+}
+
+// ParsePrimaryKeys return condition to find records with primary keys like keys of exchanged translation units, values of composite primary keys are separated by comma like the primary values of QOR Admin, e.g: "1,A"
+func ParsePrimaryKeys(scope *gorm.Scope, primaryKeys ...string) (string, []interface{}, error) {
+	var (
+		fields = primaryFields(scope)
+		tuples [][]interface{}
+	)
+
+	if len(fields) == 0 || len(primaryKeys) == 0 {
+		return "", nil, errors.New("primary key is required")
+	}
+
+	for _, primaryKey := range primaryKeys {
+		strs := strings.SplitN(primaryKey, ",", len(fields))
+		if len(strs) != len(fields) {
+			return "", nil, fmt.Errorf("invalid primary key %v", primaryKey)
+		}
+
+		var tuple []interface{}
+		for _, str := range strs {
+			tuple = append(tuple, strings.TrimSpace(str))
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	condition, values := primaryKeysCondition(scope.QuotedTableName(), quotedPrimaryKeys(scope), tuples)
+	return condition, values, nil
+}
+
+// ExportTranslationUnits export translatable fields of model's global records as translation units, with values in locale as targets
+func ExportTranslationUnits(db *gorm.DB, model interface{}, locale string) (units []TranslationUnit, err error) {
+	scope := db.NewScope(model)
+	if !IsLocalizable(scope) && !IsTranslatable(scope) {
+		return nil, fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+	}
+
+	var (
+		fields           = exchangeFields(scope)
+		modelType        = scope.GetModelStruct().ModelType
+		globalRecords    = reflect.New(reflect.SliceOf(modelType))
+		localizedRecords = reflect.New(reflect.SliceOf(modelType))
+		localized        = map[string]*gorm.Scope{}
+		isGlobal         = GetConfig(db).IsGlobal(locale)
+	)
+
+	if err = WithMode(db, ModeGlobal).Find(globalRecords.Interface()).Error; err != nil {
+		return
+	}
+
+	if !isGlobal {
+		if err = WithMode(WithLocale(db, locale), ModeLocale).Find(localizedRecords.Interface()).Error; err != nil {
+			return
+		}
+
+		for i := 0; i < localizedRecords.Elem().Len(); i++ {
+			recordScope := db.NewScope(localizedRecords.Elem().Index(i).Addr().Interface())
+			localized[recordPrimaryKey(recordScope)] = recordScope
+		}
+	}
+
+	for i := 0; i < globalRecords.Elem().Len(); i++ {
+		recordScope := db.NewScope(globalRecords.Elem().Index(i).Addr().Interface())
+		primaryKey := recordPrimaryKey(recordScope)
+
+		for _, structField := range fields {
+			field, _ := recordScope.FieldByName(structField.Name)
+			unit := TranslationUnit{
+				Key:    translationUnitKey(scope.TableName(), primaryKey, field.DBName),
+				Source: field.Field.String(),
+			}
+
+			if isGlobal {
+				unit.Target = unit.Source
+			} else if localizedScope, ok := localized[primaryKey]; ok {
+				if localizedField, ok := localizedScope.FieldByName(structField.Name); ok {
+					unit.Target = localizedField.Field.String()
+				}
+			}
+
+			if unit.Source != "" {
+				units = append(units, unit)
+			}
+		}
+	}
+	return
+}
+
+// ImportTranslationUnits save units' targets into locale, units are mapped to records with their keys, records are saved through l10n callbacks, so sync fields are kept
+func ImportTranslationUnits(db *gorm.DB, locale string, units []TranslationUnit, models ...interface{}) (result ImportResult) {
+	type recordUnits struct {
+		scope      *gorm.Scope
+		primaryKey string
+		units      []TranslationUnit
+		columns    []string
+	}
+
+	var (
+		records []*recordUnits
+		indexes = map[string]*recordUnits{}
+		scopes  = map[string]*gorm.Scope{}
+	)
+
+	for _, model := range models {
+		scope := db.NewScope(model)
+		scopes[scope.TableName()] = scope
+	}
+
+	for _, unit := range units {
+		if unit.Target == "" {
+			result.Untranslated = append(result.Untranslated, unit.Key)
+			continue
+		}
+
+		table, primaryKey, column, err := parseTranslationUnitKey(unit.Key)
+		if err != nil {
+			result.Errors = append(result.Errors, ImportError{Key: unit.Key, Err: err})
+			continue
+		}
+
+		scope, ok := scopes[table]
+		if !ok {
+			result.Errors = append(result.Errors, ImportError{Key: unit.Key, Err: fmt.Errorf("unknown table %v", table)})
+			continue
+		}
+
+		var translatable bool
+		for _, field := range exchangeFields(scope) {
+			if field.DBName == column {
+				translatable = true
+				break
+			}
+		}
+
+		if !translatable {
+			result.Errors = append(result.Errors, ImportError{Key: unit.Key, Err: fmt.Errorf("column %v is not translatable", column)})
+			continue
+		}
+
+		index := table + "/" + primaryKey
+		if _, ok := indexes[index]; !ok {
+			indexes[index] = &recordUnits{scope: scope, primaryKey: primaryKey}
+			records = append(records, indexes[index])
+		}
+		indexes[index].units = append(indexes[index].units, unit)
+		indexes[index].columns = append(indexes[index].columns, column)
+	}
+
+	for _, record := range records {
+		var keys []string
+		for _, unit := range record.units {
+			keys = append(keys, unit.Key)
+		}
+
+		if err := importRecordUnits(db, locale, record.scope, record.primaryKey, record.columns, record.units); err != nil {
+			for _, key := range keys {
+				result.Errors = append(result.Errors, ImportError{Key: key, Err: err})
+			}
+		} else {
+			result.Imported = append(result.Imported, keys...)
+		}
+	}
+	return
+}
+
+func importRecordUnits(db *gorm.DB, locale string, scope *gorm.Scope, primaryKey string, columns []string, units []TranslationUnit) error {
+	condition, primaryValues, err := ParsePrimaryKeys(scope, primaryKey)
+	if err != nil {
+		return err
+	}
+
+	// use the localized record if exists, including hidden ones, otherwise localize the global one
+	record, err := findRecordInLocale(db, scope, locale, condition, primaryValues...)
+	if err == nil && record == nil {
+		record, err = findRecordInLocale(db, scope, GetConfig(db).GlobalLocale, condition, primaryValues...)
+	}
+
+	if err != nil {
+		return err
+	} else if record == nil {
+		return fmt.Errorf("record %v not found", primaryKey)
+	}
+
+	recordScope := db.NewScope(record)
+	for idx, column := range columns {
+		if field, ok := recordScope.FieldByName(column); ok {
+			if err := field.Set(units[idx].Target); err != nil {
+				return err
+			}
+		}
+	}
+
+	return WithLocale(db, locale).Set("l10n:localize_to", locale).Save(record).Error
+}
@@ -0,0 +1,194 @@
+package l10n_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestXLIFF(t *testing.T) {
+	for _, version := range []string{l10n.XLIFF12, l10n.XLIFF20} {
+		code := "XLIFF" + version
+		product := Product{Code: code, Name: "global name", Description: "global description"}
+		dbGlobal.Create(&product)
+
+		var buf bytes.Buffer
+		checkHasErr(t, l10n.ExportXLIFF(dbGlobal.Where("code = ?", code), &buf, version, "zh", &Product{}))
+
+		content := buf.String()
+		if !strings.Contains(content, "global name") || !strings.Contains(content, "global description") || strings.Contains(content, code) {
+			t.Errorf("should export translatable fields only, but got %v", content)
+		}
+
+		// translate
+		content = strings.Replace(content, "<target></target>", "<target>中文</target>", -1)
+		content = strings.Replace(content, `<target state="needs-translation"></target>`, `<target state="translated">中文</target>`, -1)
+
+		result, err := l10n.ImportXLIFF(dbGlobal, strings.NewReader(content), &Product{})
+		checkHasErr(t, err)
+		if len(result.Imported) != 2 || len(result.Errors) != 0 {
+			t.Errorf("should import all units for XLIFF %v, but got %#v", version, result)
+		}
+
+		var productCN Product
+		dbCN.Set("l10n:mode", "locale").First(&productCN, product.ID)
+		if productCN.Name != "中文" || productCN.Description != "中文" || productCN.Code != code {
+			t.Errorf("should import translations for XLIFF %v, but got %#v", version, productCN)
+		}
+
+		buf.Reset()
+		checkHasErr(t, l10n.ExportXLIFF(dbGlobal.Where("code = ?", code), &buf, version, "zh", &Product{}))
+		if !strings.Contains(buf.String(), "中文") {
+			t.Errorf("should export localized values as targets, but got %v", buf.String())
+		}
+	}
+}
+
+func TestImportTranslationUnitsErrors(t *testing.T) {
+	product := Product{Code: "ImportErrors", Name: "global name"}
+	dbGlobal.Create(&product)
+
+	result := l10n.ImportTranslationUnits(dbGlobal, "zh", []l10n.TranslationUnit{
+		{Key: fmt.Sprintf("products/%v/name", product.ID), Target: "中文名"},
+		{Key: fmt.Sprintf("products/%v/code", product.ID), Target: "sync field"},
+		{Key: fmt.Sprintf("products/%v/unknown", product.ID), Target: "unknown field"},
+		{Key: "products/0/name", Target: "unknown record"},
+		{Key: "unknown/1/name", Target: "unknown table"},
+		{Key: fmt.Sprintf("products/%v/description", product.ID)},
+	}, &Product{})
+
+	if len(result.Imported) != 1 || len(result.Untranslated) != 1 || len(result.Errors) != 4 {
+		t.Errorf("should report errors for each unit, but got %#v", result)
+	}
+
+	var productCN Product
+	dbCN.Set("l10n:mode", "locale").First(&productCN, product.ID)
+	if productCN.Name != "中文名" || productCN.Code != "ImportErrors" {
+		t.Errorf("should import valid units, but got %#v", productCN)
+	}
+}
+
+func TestPO(t *testing.T) {
+	product := Product{Code: "PO", Name: "global \"name\"", Description: "global\ndescription"}
+	dbGlobal.Create(&product)
+	product2 := Product{Code: "PO", Name: "global name 2", Description: "global description 2"}
+	dbGlobal.Create(&product2)
+
+	var buf bytes.Buffer
+	checkHasErr(t, l10n.ExportPO(dbGlobal.Where("code = ?", "PO"), &buf, "zh", &Product{}))
+
+	content := buf.String()
+	if !strings.Contains(content, `msgid "global \"name\""`) || !strings.Contains(content, fmt.Sprintf(`msgctxt "products/%v/description"`, product.ID)) || !strings.Contains(content, `"Language: zh\n"`) {
+		t.Errorf("should export PO file, but got %v", content)
+	}
+
+	content = strings.Replace(content, "msgid \"global \\\"name\\\"\"\nmsgstr \"\"", "msgid \"global \\\"name\\\"\"\nmsgstr \"\"\n\"中文\"\n\"名\"", 1)
+	content = strings.Replace(content, "msgid \"global name 2\"\nmsgstr \"\"", "msgid \"global name 2\"\nmsgstr \"中文名 2\"", 1)
+	content = strings.Replace(content, fmt.Sprintf("msgctxt \"products/%v/description\"", product2.ID), fmt.Sprintf("#, fuzzy\nmsgctxt \"products/%v/description\"", product2.ID), 1)
+	content = strings.Replace(content, "msgid \"global description 2\"\nmsgstr \"\"", "msgid \"global description 2\"\nmsgstr \"中文描述 2\"", 1)
+
+	result, err := l10n.ImportPO(dbGlobal, strings.NewReader(content), &Product{})
+	checkHasErr(t, err)
+	if len(result.Imported) != 2 || len(result.Untranslated) != 1 || len(result.Fuzzy) != 1 || len(result.Errors) != 0 {
+		t.Errorf("should import PO file, but got %#v", result)
+	}
+
+	var productCN, product2CN Product
+	dbCN.Set("l10n:mode", "locale").First(&productCN, product.ID)
+	dbCN.Set("l10n:mode", "locale").First(&product2CN, product2.ID)
+	if productCN.Name != "中文名" || product2CN.Name != "中文名 2" || product2CN.Description != "global description 2" {
+		t.Errorf("should import translated entries only, but got %#v, %#v", productCN, product2CN)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	product := Product{Code: "CSV", Name: "global name", Description: "global description"}
+	dbGlobal.Create(&product)
+	product2 := Product{Code: "CSV", Name: "global name 2"}
+	dbGlobal.Create(&product2)
+
+	var buf bytes.Buffer
+	checkHasErr(t, l10n.ExportCSV(dbGlobal.Where("code = ?", "CSV"), &buf, &Product{}, "zh"))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	checkHasErr(t, err)
+	if len(rows) != 3 || strings.Join(rows[0], ",") != "id,code (en-US),name (en-US),name (zh),description (en-US),description (zh)" {
+		t.Fatalf("should export one row for each record, but got %v", rows)
+	}
+
+	rows[1][3] = "中文名"
+	rows[2][3] = "中文名 2"
+	rows[2][1] = "edited code"
+	rows = append(rows, []string{"0", "", "", "unknown record"})
+
+	buf.Reset()
+	csv.NewWriter(&buf).WriteAll(rows)
+	content := buf.String()
+
+	report, err := l10n.ImportCSV(dbGlobal, strings.NewReader(content), &Product{}, l10n.SpreadsheetImportOptions{DryRun: true})
+	checkHasErr(t, err)
+	if report.Locale != "zh" || len(report.Changes) != 1 || report.Changes[0].New != "中文名" || len(report.Errors) != 2 {
+		t.Errorf("should report changes and errors, but got %#v", report)
+	}
+
+	var count int
+	dbCN.Set("l10n:mode", "locale").Model(&Product{}).Where("id = ?", product.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("should not save changes when dry run")
+	}
+
+	if _, err := l10n.ImportCSV(dbGlobal, strings.NewReader(content), &Product{}, l10n.SpreadsheetImportOptions{EditableLocales: []string{"ja"}}); err == nil {
+		t.Errorf("should not import locales that are not editable")
+	}
+
+	report, err = l10n.ImportCSV(dbGlobal, strings.NewReader(content), &Product{}, l10n.SpreadsheetImportOptions{EditableLocales: []string{"zh"}})
+	checkHasErr(t, err)
+
+	var productCN, product2CN Product
+	dbCN.Set("l10n:mode", "locale").First(&productCN, product.ID)
+	if productCN.Name != "中文名" || productCN.Code != "CSV" {
+		t.Errorf("should import changes, but got %#v", productCN)
+	}
+
+	if !dbCN.Set("l10n:mode", "locale").First(&product2CN, product2.ID).RecordNotFound() {
+		t.Errorf("should not import rows that have errors")
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	product := Product{Code: "XLSX", Name: "global <name> & more"}
+	dbGlobal.Create(&product)
+
+	var buf bytes.Buffer
+	checkHasErr(t, l10n.ExportXLSX(dbGlobal.Where("code = ?", "XLSX"), &buf, &Product{}, "zh"))
+
+	report, err := l10n.ImportXLSX(dbGlobal, bytes.NewReader(buf.Bytes()), &Product{}, l10n.SpreadsheetImportOptions{})
+	checkHasErr(t, err)
+	if len(report.Changes) != 0 || len(report.Errors) != 0 {
+		t.Errorf("should have no changes for unedited spreadsheet, but got %#v", report)
+	}
+}
+
+func TestXLSXRowNumbers(t *testing.T) {
+	// spreadsheet applications omit empty rows, the second row is empty here
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	checkHasErr(t, err)
+	fmt.Fprint(file, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+
+		`<row r="1"><c r="A1" t="inlineStr"><is><t>id</t></is></c><c r="B1" t="inlineStr"><is><t>name (zh)</t></is></c></row>`+
+		`<row r="3"><c r="A3"><v>0</v></c><c r="B3" t="inlineStr"><is><t>unknown record</t></is></c></row>`+
+		`</sheetData></worksheet>`)
+	checkHasErr(t, archive.Close())
+
+	report, err := l10n.ImportXLSX(dbGlobal, bytes.NewReader(buf.Bytes()), &Product{}, l10n.SpreadsheetImportOptions{DryRun: true})
+	checkHasErr(t, err)
+	if len(report.Errors) != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("should report errors with row numbers of the spreadsheet, but got %#v", report.Errors)
+	}
+}
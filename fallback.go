@@ -0,0 +1,177 @@
+package l10n
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/qor/qor/utils"
+)
+
+// Fallbacks configure fallback chains for locales, if a record hasn't been localized to the requested locale, it will try locales in the chain one by one before falling back to the global locale, e.g:
+//
+//	l10n.Fallbacks["zh-HK"] = []string{"zh-TW", "zh"}
+var Fallbacks = map[string][]string{}
+
+// FallbackToParentLocales if enabled, locales that haven't configured fallbacks will fall back to their parents in `Locales`, or their BCP 47 parent tags if not registered, e.g: zh-Hant-HK -> zh-Hant -> zh
+var FallbackToParentLocales bool
+
+// FallbackLocales return locale's fallback chain with the default configuration, from most specific to least specific, always ended with the global locale
+func FallbackLocales(locale string) []string {
+	return GetConfig(nil).FallbackLocales(locale)
+}
+
+// FallbackLocales return locale's fallback chain, from most specific to least specific, always ended with the global locale
+func (config *Config) FallbackLocales(locale string) (locales []string) {
+	fallbacks, ok := config.Fallbacks[locale]
+	if !ok && config.FallbackToParentLocales {
+		fallbacks = parentLocales(config.Locales, locale)
+	}
+
+	for _, fallback := range fallbacks {
+		if fallback != "" && fallback != locale && fallback != config.GlobalLocale && !includeLocale(locales, fallback) {
+			locales = append(locales, fallback)
+		}
+	}
+
+	if locale != config.GlobalLocale {
+		locales = append(locales, config.GlobalLocale)
+	}
+	return
+}
+
+func parentLocales(registry LocaleRegistry, locale string) (parents []string) {
+	// follow parents of registered locales
+	if parents = registry.parents(locale); len(parents) > 0 {
+		return
+	}
+
+	for idx := strings.LastIndex(locale, "-"); idx > 0; idx = strings.LastIndex(locale, "-") {
+		locale = locale[:idx]
+		parents = append(parents, locale)
+	}
+	return
+}
+
+func includeLocale(locales []string, locale string) bool {
+	for _, l := range locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+func isFallbackField(field *gorm.StructField) bool {
+	if _, ok := utils.ParseTagOption(field.Tag.Get("l10n"))["FALLBACK"]; ok {
+		return true
+	}
+	return false
+}
+
+// fillFallbackFields fill blank fallback fields of localized records with values from their fallback locales
+func fillFallbackFields(scope *gorm.Scope, locale string) {
+	var fallbackFields []*gorm.StructField
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal && isFallbackField(field) && !isSyncField(field) {
+			fallbackFields = append(fallbackFields, field)
+		}
+	}
+
+	var primaryStructFields []*gorm.StructField
+	for _, field := range scope.GetModelStruct().PrimaryFields {
+		if field.Name != "LanguageCode" {
+			primaryStructFields = append(primaryStructFields, field)
+		}
+	}
+
+	if len(fallbackFields) == 0 || len(primaryStructFields) == 0 {
+		return
+	}
+
+	var (
+		records     []reflect.Value
+		primaryKeys [][]interface{}
+		config      = getConfig(scope)
+		locales     = append([]string{locale}, config.FallbackLocales(locale)...)
+		results     = scope.IndirectValue()
+	)
+
+	primaryKeyOf := func(record reflect.Value) (values []interface{}) {
+		for _, field := range primaryStructFields {
+			values = append(values, record.FieldByName(field.Name).Interface())
+		}
+		return
+	}
+
+	hasBlankField := func(record reflect.Value) bool {
+		for _, field := range fallbackFields {
+			if isBlankValue(record.FieldByName(field.Name)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	collect := func(record reflect.Value) {
+		record = reflect.Indirect(record)
+		if record.Kind() == reflect.Struct && record.FieldByName("LanguageCode").String() != config.GlobalLocale && hasBlankField(record) {
+			records = append(records, record)
+			primaryKeys = append(primaryKeys, primaryKeyOf(record))
+		}
+	}
+
+	if results.Kind() == reflect.Slice {
+		for i := 0; i < results.Len(); i++ {
+			collect(results.Index(i))
+		}
+	} else {
+		collect(results)
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	fallbackResults := reflect.New(reflect.SliceOf(scope.GetModelStruct().ModelType))
+	condition, primaryValues := primaryKeysCondition(scope.QuotedTableName(), quotedPrimaryKeys(scope), primaryKeys)
+	if err := scope.NewDB().Set("l10n:mode", ModeUnscoped).Where(condition, primaryValues...).Where(fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope))), localeValues(scope, locales[1:])).Find(fallbackResults.Interface()).Error; err != nil {
+		scope.Err(err)
+		return
+	}
+
+	fallbackRecords := map[string]reflect.Value{}
+	for i := 0; i < fallbackResults.Elem().Len(); i++ {
+		fallbackRecord := fallbackResults.Elem().Index(i)
+		fallbackRecords[fmt.Sprintf("%v@%v", primaryKeyString(primaryKeyOf(fallbackRecord)), fallbackRecord.FieldByName("LanguageCode").String())] = fallbackRecord
+	}
+
+	for _, record := range records {
+		var (
+			primaryKey   = primaryKeyString(primaryKeyOf(record))
+			recordLocale = record.FieldByName("LanguageCode").String()
+			started      bool
+		)
+
+		for _, fallback := range locales {
+			// only use locales after the record's locale in the fallback chain
+			if !started {
+				started = fallback == recordLocale
+				continue
+			}
+
+			if fallbackRecord, ok := fallbackRecords[fmt.Sprintf("%v@%v", primaryKey, fallback)]; ok {
+				for _, field := range fallbackFields {
+					if value := record.FieldByName(field.Name); isBlankValue(value) && value.CanSet() {
+						value.Set(fallbackRecord.FieldByName(field.Name))
+					}
+				}
+			}
+		}
+	}
+}
+
+func isBlankValue(value reflect.Value) bool {
+	return reflect.DeepEqual(value.Interface(), reflect.Zero(value.Type()).Interface())
+}
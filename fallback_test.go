@@ -0,0 +1,96 @@
+package l10n_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestFallbackLocales(t *testing.T) {
+	l10n.Fallbacks["zh-HK"] = []string{"zh-TW", "zh", l10n.Global}
+	defer delete(l10n.Fallbacks, "zh-HK")
+
+	if locales := l10n.FallbackLocales("zh-HK"); !reflect.DeepEqual(locales, []string{"zh-TW", "zh", l10n.Global}) {
+		t.Errorf("should use configured fallbacks, but got %v", locales)
+	}
+
+	if locales := l10n.FallbackLocales("zh-Hant-TW"); !reflect.DeepEqual(locales, []string{l10n.Global}) {
+		t.Errorf("should only fall back to global locale by default, but got %v", locales)
+	}
+
+	l10n.FallbackToParentLocales = true
+	defer func() { l10n.FallbackToParentLocales = false }()
+
+	if locales := l10n.FallbackLocales("zh-Hant-TW"); !reflect.DeepEqual(locales, []string{"zh-Hant", "zh", l10n.Global}) {
+		t.Errorf("should fall back to parent locales, but got %v", locales)
+	}
+}
+
+func TestQueryWithFallbackLocales(t *testing.T) {
+	l10n.Fallbacks["zh-HK"] = []string{"zh-TW", "zh"}
+	defer delete(l10n.Fallbacks, "zh-HK")
+
+	product := Product{Code: "Fallback", Name: "global"}
+	dbGlobal.Create(&product)
+	product.Name = "zh"
+	dbGlobal.Set("l10n:locale", "zh").Create(&product)
+	product.Name = "zh-TW"
+	dbGlobal.Set("l10n:locale", "zh-TW").Create(&product)
+
+	product2 := Product{Code: "Fallback2", Name: "global"}
+	dbGlobal.Create(&product2)
+	product2.Name = "zh"
+	dbGlobal.Set("l10n:locale", "zh").Create(&product2)
+
+	product3 := Product{Code: "Fallback3", Name: "global"}
+	dbGlobal.Create(&product3)
+
+	dbHK := dbGlobal.Set("l10n:locale", "zh-HK")
+	for _, expected := range []struct {
+		ID     int
+		Locale string
+	}{{product.ID, "zh-TW"}, {product2.ID, "zh"}, {product3.ID, l10n.Global}} {
+		var result Product
+		if err := dbHK.First(&result, expected.ID).Error; err != nil {
+			t.Errorf("failed to find product %v, got %v", expected.ID, err)
+		} else if result.LanguageCode != expected.Locale {
+			t.Errorf("should find product %v in locale %v, but got %v", expected.ID, expected.Locale, result.LanguageCode)
+		}
+	}
+
+	var count int
+	if dbHK.Model(&Product{}).Where("code IN (?)", []string{"Fallback", "Fallback2", "Fallback3"}).Count(&count); count != 3 {
+		t.Errorf("should find one record for each product, but got %v", count)
+	}
+}
+
+func TestQueryWithFallbackFields(t *testing.T) {
+	product := Product{Code: "FallbackFields", Name: "global", Description: "global description"}
+	dbGlobal.Create(&product)
+	product.Name = "中文名"
+	product.Description = ""
+	dbCN.Create(&product)
+
+	var productCN Product
+	dbCN.First(&productCN, product.ID)
+	if productCN.LanguageCode != "zh" || productCN.Name != "中文名" {
+		t.Errorf("should find localized zh product, but got %v %v", productCN.LanguageCode, productCN.Name)
+	}
+
+	if productCN.Description != "global description" {
+		t.Errorf("blank fallback field should be filled from global product, but got %q", productCN.Description)
+	}
+
+	var productsCN []Product
+	dbCN.Where("code = ?", "FallbackFields").Find(&productsCN)
+	if len(productsCN) != 1 || productsCN[0].Description != "global description" {
+		t.Errorf("blank fallback field should be filled when finding slice")
+	}
+
+	var localeProductCN Product
+	dbCN.Set("l10n:mode", "locale").First(&localeProductCN, product.ID)
+	if localeProductCN.Description != "" {
+		t.Errorf("should not fill fallback fields with locale mode, but got %q", localeProductCN.Description)
+	}
+}
@@ -0,0 +1,142 @@
+package l10n
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Hideable embed this struct with `l10n.Locale` into models to allow hiding records in some locales, e.g: a product that is not sold in `de-DE`
+//
+//	type Product struct {
+//	  gorm.Model
+//	  Name string
+//	  l10n.Locale
+//	  l10n.Hideable
+//	}
+//
+// A hidden record is a localized record marked as hidden, it is excluded from the fallback, locale and stale modes, and it stops falling back to the global record, locales falling back to the locale are hidden too unless they have their own localized records
+type Hideable struct {
+	L10nHidden bool
+	// L10nPlaceholder the record is a placeholder copied from the global record when hiding a record that hasn't been localized, it is deleted when unhiding, so the locale falls back to the global record again, saving it in the locale makes it a localized record
+	L10nPlaceholder bool
+}
+
+func (Hideable) hideable() {}
+
+type hideableInterface interface {
+	hideable()
+}
+
+func isHideable(scope *gorm.Scope) (ok bool) {
+	if scope.GetModelStruct().ModelType == nil {
+		return false
+	}
+	_, ok = reflect.New(scope.GetModelStruct().ModelType).Interface().(hideableInterface)
+	return
+}
+
+// Hide hide record in locales, records that haven't been localized are localized from the global record as hidden, it runs in a transaction
+func Hide(db *gorm.DB, record interface{}, locales ...string) ([]LocalizeResult, error) {
+	return setHidden(db, record, true, locales...)
+}
+
+// Unhide unhide record in locales, the hidden records become normal localized records, it runs in a transaction
+func Unhide(db *gorm.DB, record interface{}, locales ...string) ([]LocalizeResult, error) {
+	return setHidden(db, record, false, locales...)
+}
+
+func setHidden(db *gorm.DB, record interface{}, hidden bool, locales ...string) (results []LocalizeResult, err error) {
+	scope := db.NewScope(record)
+	if !IsLocalizable(scope) || !isHideable(scope) {
+		return nil, fmt.Errorf("l10n: %v can't be hidden", scope.GetModelStruct().ModelType.Name())
+	}
+
+	err = transaction(db, func(tx *gorm.DB) error {
+		for _, locale := range locales {
+			result := LocalizeResult{Locale: locale}
+			result.Status, result.Err = setRecordHidden(tx, record, locale, hidden)
+			results = append(results, result)
+			if result.Err != nil {
+				return result.Err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func setRecordHidden(tx *gorm.DB, record interface{}, locale string, hidden bool) (LocalizeStatus, error) {
+	if GetConfig(tx).IsGlobal(locale) {
+		return LocalizeFailed, fmt.Errorf("l10n: global record can't be hidden")
+	}
+
+	scope := tx.NewScope(record)
+	condition, primaryKeys, err := primaryKeyCondition(scope)
+	if err != nil {
+		return LocalizeFailed, err
+	}
+
+	status := LocalizeHidden
+	if !hidden {
+		status = LocalizeUnhidden
+	}
+
+	existing := reflect.New(scope.GetModelStruct().ModelType).Interface()
+	localeCondition := fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope)))
+	if !WithMode(tx, ModeUnscoped).Unscoped().Where(condition, primaryKeys...).Where(localeCondition, LocaleValue(scope, locale)).First(existing).RecordNotFound() {
+		var (
+			existingScope = tx.NewScope(existing)
+			columns       = map[string]interface{}{"l10n_hidden": hidden}
+		)
+
+		// placeholders created when hiding are deleted, instead of becoming stale copies of the global record
+		if field, ok := existingScope.FieldByName("L10nPlaceholder"); ok && field.Field.Bool() && !hidden {
+			if err := tx.Table(scope.TableName()).Where(condition, primaryKeys...).Where(fmt.Sprintf("%v = ?", scope.Quote(LanguageColumn(scope))), LocaleValue(scope, locale)).Delete(nil).Error; err != nil {
+				return LocalizeFailed, err
+			}
+			return status, nil
+		}
+
+		deletedAt, hasDeletedAt := existingScope.FieldByName("DeletedAt")
+		if hidden && hasDeletedAt && !deletedAt.IsBlank {
+			// a deleted localized record is restored as hidden, otherwise the record falls back to the global record
+			columns["deleted_at"] = nil
+		} else if field, ok := existingScope.FieldByName("L10nHidden"); ok && field.Field.Bool() == hidden {
+			return LocalizeSkipped, nil
+		}
+
+		if err := tx.Table(scope.TableName()).Where(condition, primaryKeys...).Where(fmt.Sprintf("%v = ?", scope.Quote(LanguageColumn(scope))), LocaleValue(scope, locale)).UpdateColumns(columns).Error; err != nil {
+			return LocalizeFailed, err
+		}
+		return status, nil
+	}
+
+	if !hidden {
+		return LocalizeSkipped, nil
+	}
+
+	// localize the global record as hidden
+	globalLocale := GetConfig(tx).GlobalLocale
+	global, err := findLocalizedRecord(tx, record, globalLocale)
+	if err != nil {
+		return LocalizeFailed, err
+	} else if global == nil {
+		return LocalizeFailed, fmt.Errorf("l10n: record is not found in %v", globalLocale)
+	}
+
+	globalScope := tx.NewScope(global)
+	if field, ok := globalScope.FieldByName("L10nHidden"); ok {
+		field.Set(true)
+	}
+
+	if field, ok := globalScope.FieldByName("L10nPlaceholder"); ok {
+		field.Set(true)
+	}
+
+	if err := WithLocale(tx, locale).Set("l10n:localize_to", locale).Create(global).Error; err != nil {
+		return LocalizeFailed, err
+	}
+	return status, nil
+}
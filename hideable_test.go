@@ -0,0 +1,165 @@
+package l10n_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestHideable(t *testing.T) {
+	banner := Banner{Title: "global"}
+	dbGlobal.Create(&banner)
+
+	results, err := l10n.Hide(dbGlobal, &banner, "zh")
+	checkHasErr(t, err)
+	if len(results) != 1 || results[0].Status != l10n.LocalizeHidden {
+		t.Errorf("should hide record in locale, but got %#v", results)
+	}
+
+	if !dbCN.First(&Banner{}, banner.ID).RecordNotFound() {
+		t.Errorf("hidden record should not fall back to the global record")
+	}
+
+	if !dbCN.Set("l10n:mode", "locale").First(&Banner{}, banner.ID).RecordNotFound() {
+		t.Errorf("hidden record should be excluded in locale mode")
+	}
+
+	if !dbCN.Set("l10n:mode", "reverse").First(&Banner{}, banner.ID).RecordNotFound() {
+		t.Errorf("hidden record should not be listed as unlocalized")
+	}
+
+	var hidden Banner
+	if dbCN.Set("l10n:mode", "hidden").First(&hidden, banner.ID).RecordNotFound() || !hidden.L10nHidden || hidden.Title != "global" {
+		t.Errorf("should find hidden record in hidden mode, but got %#v", hidden)
+	}
+
+	if dbEN.First(&Banner{}, banner.ID).RecordNotFound() {
+		t.Errorf("record should still be visible in other locales")
+	}
+
+	// hidden in fallback locales
+	l10n.Fallbacks["zh-TW"] = []string{"zh"}
+	defer delete(l10n.Fallbacks, "zh-TW")
+	if !dbGlobal.Set("l10n:locale", "zh-TW").First(&Banner{}, banner.ID).RecordNotFound() {
+		t.Errorf("record hidden in fallback locale should be hidden too")
+	}
+
+	if results, _ = l10n.Hide(dbGlobal, &banner, "zh"); results[0].Status != l10n.LocalizeSkipped {
+		t.Errorf("should skip hidden record, but got %#v", results)
+	}
+
+	results, err = l10n.Unhide(dbGlobal, &banner, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeUnhidden {
+		t.Errorf("should unhide record, but got %#v", results)
+	}
+
+	// the placeholder created when hiding is deleted, so the record falls back to the global record again
+	banner.Title = "global updated"
+	dbGlobal.Save(&banner)
+
+	var bannerCN Banner
+	if dbCN.First(&bannerCN, banner.ID).RecordNotFound() || bannerCN.LanguageCode != l10n.Global || bannerCN.Title != "global updated" {
+		t.Errorf("unhidden record should fall back to the global record, but got %#v", bannerCN)
+	}
+
+	// localized records that are hidden stay localized after unhiding
+	banner.Title = "中文"
+	dbCN.Save(&banner)
+	l10n.Hide(dbGlobal, &banner, "zh")
+	l10n.Unhide(dbGlobal, &banner, "zh")
+
+	var localizedCN Banner
+	if dbCN.First(&localizedCN, banner.ID).RecordNotFound() || localizedCN.LanguageCode != "zh" || localizedCN.Title != "中文" || localizedCN.L10nHidden {
+		t.Errorf("unhidden localized record should be a localized record, but got %#v", localizedCN)
+	}
+
+	if _, err = l10n.Hide(dbGlobal, &banner, l10n.Global); err == nil {
+		t.Errorf("global record can't be hidden")
+	}
+
+	if err = dbCN.Set("l10n:mode", "hidden").Find(&[]Product{}).Error; err == nil {
+		t.Errorf("should return error when querying hidden records of models that aren't hideable")
+	}
+}
+
+func TestLocalizeHiddenRecord(t *testing.T) {
+	banner := Banner{Title: "global"}
+	dbGlobal.Create(&banner)
+
+	_, err := l10n.Hide(dbGlobal, &banner, "zh")
+	checkHasErr(t, err)
+
+	results, err := l10n.Localize(l10n.WithLocalizeOptions(dbGlobal, l10n.LocalizeOptions{SkipExisting: true}), &banner, l10n.Global, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeSkipped {
+		t.Errorf("hidden record should be treated as localized, but got %#v", results)
+	}
+
+	banner.Title = "global updated"
+	dbGlobal.Save(&banner)
+	results, err = l10n.Localize(dbGlobal, &banner, l10n.Global, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeOverwritten {
+		t.Errorf("hidden record should be overwritten, but got %#v", results)
+	}
+
+	result := l10n.ImportTranslationUnits(dbGlobal, "zh", []l10n.TranslationUnit{
+		{Key: fmt.Sprintf("banners/%v/title", banner.ID), Target: "中文"},
+	}, &Banner{})
+	if len(result.Imported) != 1 || len(result.Errors) != 0 {
+		t.Errorf("should import translations into hidden record, but got %#v", result)
+	}
+
+	var hidden Banner
+	if dbCN.Set("l10n:mode", "hidden").First(&hidden, banner.ID).RecordNotFound() || hidden.Title != "中文" || hidden.L10nPlaceholder {
+		t.Errorf("record should keep hidden after localizing and importing, but got %#v", hidden)
+	}
+
+	var count int
+	dbGlobal.Set("l10n:mode", "unscoped").Model(&Banner{}).Where("id = ?", banner.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("should not create another localized record, but got %v records", count)
+	}
+}
+
+func TestLocalizationStatusOfHiddenRecord(t *testing.T) {
+	banner := Banner{Title: "global"}
+	dbGlobal.Create(&banner)
+	banner.Title = "english"
+	dbEN.Save(&banner)
+
+	_, err := l10n.Hide(dbGlobal, &banner, "zh")
+	checkHasErr(t, err)
+
+	statuses, err := l10n.GetLocalizationStatuses(dbGlobal, &banner, []string{"zh", "en", "ja"})
+	checkHasErr(t, err)
+
+	states := map[string]l10n.LocalizationState{}
+	for _, status := range statuses {
+		states[status.Locale] = status.State
+	}
+
+	if states["zh"] != l10n.LocalizationHidden || states["en"] != l10n.LocalizationLocalized || states["ja"] != l10n.LocalizationMissing {
+		t.Errorf("hidden record should have hidden status, but got %#v", statuses)
+	}
+}
+
+func TestCoverageOfHiddenRecords(t *testing.T) {
+	banner := Banner{Title: "global"}
+	dbGlobal.Create(&banner)
+
+	reports, err := l10n.TableCoverage(dbGlobal, "banners", "id", "ja")
+	checkHasErr(t, err)
+	before := reports[0]
+
+	_, err = l10n.Hide(dbGlobal, &banner, "ja")
+	checkHasErr(t, err)
+
+	reports, err = l10n.TableCoverage(dbGlobal, "banners", "id", "ja")
+	checkHasErr(t, err)
+	if report := reports[0]; report.Hidden != before.Hidden+1 || report.Localized != before.Localized || report.Missing != before.Missing-1 {
+		t.Errorf("hidden records should not be counted as localized, but got %#v, before hiding %#v", report, before)
+	}
+}
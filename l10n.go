@@ -3,10 +3,13 @@ package l10n
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/jinzhu/gorm"
 	"github.com/qor/admin"
 	"github.com/qor/qor"
 	"github.com/qor/qor/resource"
@@ -14,7 +17,7 @@ import (
 	"github.com/qor/roles"
 )
 
-// Global global language
+// Global global language, used as the global locale of DBs that haven't configured it with `Config`
 var Global = "en-US"
 
 type l10nInterface interface {
@@ -27,9 +30,9 @@ type Locale struct {
 	LanguageCode string `sql:"size:20" gorm:"primary_key"`
 }
 
-// IsGlobal return if current locale is global
+// IsGlobal return if current locale is the global locale of the default configuration, use `Config.IsGlobal` for DBs with configured global locales, e.g: l10n.GetConfig(db).IsGlobal(product.LanguageCode)
 func (l Locale) IsGlobal() bool {
-	return l.LanguageCode == Global
+	return GetConfig(nil).IsGlobal(l.LanguageCode)
 }
 
 // SetLocale set model's locale
@@ -57,26 +60,28 @@ type editableLocalesInterface interface {
 	EditableLocales() []string
 }
 
-func getAvailableLocales(req *http.Request, currentUser interface{}) []string {
-	if user, ok := currentUser.(viewableLocalesInterface); ok {
+// AvailableLocales return locales that the current user could view, from the user's `ViewableLocales` or `AvailableLocales` method, only the global locale if the user has neither
+func AvailableLocales(context *qor.Context) []string {
+	if user, ok := context.CurrentUser.(viewableLocalesInterface); ok {
 		return user.ViewableLocales()
 	}
 
-	if user, ok := currentUser.(availableLocalesInterface); ok {
+	if user, ok := context.CurrentUser.(availableLocalesInterface); ok {
 		return user.AvailableLocales()
 	}
-	return []string{Global}
+	return []string{GetConfig(context.GetDB()).GlobalLocale}
 }
 
-func getEditableLocales(req *http.Request, currentUser interface{}) []string {
-	if user, ok := currentUser.(editableLocalesInterface); ok {
+// EditableLocales return locales that the current user could edit, from the user's `EditableLocales` or `AvailableLocales` method, only the global locale if the user has neither
+func EditableLocales(context *qor.Context) []string {
+	if user, ok := context.CurrentUser.(editableLocalesInterface); ok {
 		return user.EditableLocales()
 	}
 
-	if user, ok := currentUser.(availableLocalesInterface); ok {
+	if user, ok := context.CurrentUser.(availableLocalesInterface); ok {
 		return user.AvailableLocales()
 	}
-	return []string{Global}
+	return []string{GetConfig(context.GetDB()).GlobalLocale}
 }
 
 func getLocaleFromContext(context *qor.Context) string {
@@ -84,32 +89,238 @@ func getLocaleFromContext(context *qor.Context) string {
 		return locale
 	}
 
-	return Global
+	return GetConfig(context.GetDB()).GlobalLocale
 }
 
 // LocalizeActionArgument localize action's argument
 type LocalizeActionArgument struct {
 	From string
 	To   []string
+	// Fields translatable fields to copy, all translatable fields are copied if blank
+	Fields []string
+	// SkipExisting skip records that have been localized, otherwise they will be overwritten
+	SkipExisting bool
+}
+
+func (arg *LocalizeActionArgument) localizeActionArgument() *LocalizeActionArgument {
+	return arg
+}
+
+type localizeActionArgumentInterface interface {
+	localizeActionArgument() *LocalizeActionArgument
+}
+
+// ConfigureLocalizeArgumentResource configure metas `From`, `To`, `Fields` and `SkipExisting` of argumentResource to localize records of res, and validate the user could view `From` and edit `To` locales, argumentResource's value need to be a `LocalizeActionArgument` or embed it, e.g: arguments of localize jobs of qor worker
+func ConfigureLocalizeArgumentResource(argumentResource *admin.Resource, res *admin.Resource) {
+	argumentResource.Meta(&admin.Meta{
+		Name: "From",
+		Type: "select_one",
+		Valuer: func(_ interface{}, context *qor.Context) interface{} {
+			return GetConfig(context.GetDB()).GlobalLocale
+		},
+		Collection: func(value interface{}, context *qor.Context) (results [][]string) {
+			for _, locale := range AvailableLocales(context) {
+				results = append(results, []string{locale, locale})
+			}
+			return
+		},
+	})
+
+	argumentResource.Meta(&admin.Meta{
+		Name: "To",
+		Type: "select_many",
+		Valuer: func(_ interface{}, context *qor.Context) interface{} {
+			return []string{getLocaleFromContext(context)}
+		},
+		Collection: func(value interface{}, context *qor.Context) (results [][]string) {
+			for _, locale := range EditableLocales(context) {
+				results = append(results, []string{locale, locale})
+			}
+			return
+		},
+	})
+
+	argumentResource.Meta(&admin.Meta{
+		Name: "Fields",
+		Type: "select_many",
+		Collection: func(value interface{}, context *qor.Context) (results [][]string) {
+			for _, name := range TranslatableFieldNames(context.GetDB().NewScope(res.Value)) {
+				results = append(results, []string{name, name})
+			}
+			return
+		},
+	})
+
+	argumentResource.Meta(&admin.Meta{
+		Name:  "SkipExisting",
+		Label: "Skip already localized records",
+		Type:  "checkbox",
+	})
+
+	argumentResource.AddValidator(func(record interface{}, metaValues *resource.MetaValues, context *qor.Context) error {
+		if arg, ok := record.(localizeActionArgumentInterface); ok {
+			return validateLocalizeArgument(context, arg.localizeActionArgument())
+		}
+		return nil
+	})
+}
+
+func validateLocalizeArgument(context *qor.Context, arg *LocalizeActionArgument) error {
+	if !includeLocale(AvailableLocales(context), arg.From) {
+		return fmt.Errorf("locale %v is not available", arg.From)
+	}
+	return ValidateEditableLocales(context, arg.To)
+}
+
+// ValidateEditableLocales return an error if the current user couldn't edit any of locales
+func ValidateEditableLocales(context *qor.Context, locales []string) error {
+	editableLocales := EditableLocales(context)
+	for _, locale := range locales {
+		if !includeLocale(editableLocales, locale) {
+			return fmt.Errorf("locale %v is not editable", locale)
+		}
+	}
+	return nil
+}
+
+var (
+	resourceConfigurators []func(res *admin.Resource)
+	configuredResources   []*admin.Resource
+	configuratorsMutex    sync.Mutex
+)
+
+// RegisterResourceConfigurator register a function to configure localizable resources for Qor Admin, it will be called for resources that have been configured and ones configured later, used to integrate with other QOR modules, e.g: qor worker
+func RegisterResourceConfigurator(configurator func(res *admin.Resource)) {
+	configuratorsMutex.Lock()
+	resourceConfigurators = append(resourceConfigurators, configurator)
+	resources := append([]*admin.Resource{}, configuredResources...)
+	configuratorsMutex.Unlock()
+
+	for _, res := range resources {
+		configurator(res)
+	}
 }
 
 // ConfigureQorResource configure qor locale for Qor Admin
 func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
+	configureQorResource(res)
+}
+
+// LocaleResource embed this struct into models that define their own `LanguageCode` field instead of embedding `Locale`, e.g: to save locales into another column with gorm's `column` tag, or as IDs of a locales table with a custom type, to configure their resources for Qor Admin like `Locale`, the models need to implement `IsGlobal` and `SetLocale` too
+type LocaleResource struct{}
+
+// ConfigureQorResource configure qor locale for Qor Admin
+func (LocaleResource) ConfigureQorResource(res resource.Resourcer) {
+	configureQorResource(res)
+}
+
+// localizationURL return URL of record's edit page in locale, the record's language code in the primary key query is removed, so it won't override the locale
+func localizationURL(context *admin.Context, value interface{}, locale string) string {
+	u, err := url.Parse(context.URLFor(value))
+	if err != nil {
+		return ""
+	}
+
+	var (
+		query            = u.Query()
+		primaryKeyRegexp = regexp.MustCompile(fmt.Sprintf(`primary_key\[.+_%v\]`, regexp.QuoteMeta(LanguageColumn(context.GetDB().NewScope(value)))))
+	)
+
+	for key := range query {
+		if primaryKeyRegexp.MatchString(key) {
+			query.Del(key)
+		}
+	}
+	query.Set("locale", locale)
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/edit"
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// registerLocaleFuncMaps register helpers to display locales with the registry of Admin's DB
+// runRecordsAction find records of res selected by argument with db, records not found are skipped in locales, call fc for each of them in one transaction, so nothing is changed if any of them failed, and flash the summary with message
+func runRecordsAction(res *admin.Resource, argument *admin.ActionArgument, name string, db *gorm.DB, locales []string, fc func(tx *gorm.DB, record interface{}) ([]LocalizeResult, error), message func(summary LocalizeSummary) string) error {
+	var (
+		results   = res.NewSlice()
+		summary   = LocalizeSummary{}
+		sqls      []string
+		sqlParams []interface{}
+	)
+
+	for _, primaryValue := range argument.PrimaryValues {
+		primaryQuerySQL, primaryParams := res.ToPrimaryQueryParams(primaryValue, argument.Context.Context)
+		sqls = append(sqls, primaryQuerySQL)
+		sqlParams = append(sqlParams, primaryParams...)
+	}
+
+	if err := db.Where(strings.Join(sqls, " OR "), sqlParams...).Find(results).Error; err != nil {
+		return err
+	}
+
+	reflectResults := reflect.Indirect(reflect.ValueOf(results))
+	if missing := len(argument.PrimaryValues) - reflectResults.Len(); missing > 0 {
+		summary[LocalizeSkipped] += missing * len(locales)
+	}
+
+	err := transaction(argument.Context.GetDB(), func(tx *gorm.DB) error {
+		for i := 0; i < reflectResults.Len(); i++ {
+			record := reflectResults.Index(i).Interface()
+			recordResults, err := fc(tx, record)
+			summary.Add(recordResults...)
+			if err != nil {
+				// report the failed record and locale only, as other changes are rolled back
+				for _, result := range recordResults {
+					if result.Err != nil {
+						return fmt.Errorf("%v in %v: %v", recordPrimaryKey(tx.NewScope(record)), result.Locale, result.Err)
+					}
+				}
+				return fmt.Errorf("%v: %v", recordPrimaryKey(tx.NewScope(record)), err)
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to %v, all changes have been rolled back: %v", strings.ToLower(name), err)
+	}
+
+	argument.Context.Flash(message(summary), "success")
+	return nil
+}
+
+func registerLocaleFuncMaps(Admin *admin.Admin) {
+	registry := GetConfig(Admin.DB).Locales
+	Admin.RegisterFuncMap("locale_name", registry.Name)
+	Admin.RegisterFuncMap("locale_direction", registry.Direction)
+}
+
+func configureQorResource(res resource.Resourcer) {
 	if res, ok := res.(*admin.Resource); ok {
 		Admin := res.GetAdmin()
+		config := GetConfig(Admin.DB)
 		res.UseTheme("l10n")
 
+		defer func() {
+			configuratorsMutex.Lock()
+			configuredResources = append(configuredResources, res)
+			configurators := append([]func(res *admin.Resource){}, resourceConfigurators...)
+			configuratorsMutex.Unlock()
+
+			for _, configurator := range configurators {
+				configurator(res)
+			}
+		}()
+
 		if res.Permission == nil {
 			res.Permission = roles.NewPermission()
 		}
-		res.Permission.Allow(roles.CRUD, "locale_admin").Allow(roles.Read, "locale_reader")
+		res.Permission.Allow(roles.CRUD, config.AdminRoleName).Allow(roles.Read, config.ReaderRoleName)
 
+		// localization statuses are loaded for all records of the page when querying them, with the cache set by the l10n middleware
 		res.Meta(&admin.Meta{Name: "Localization", Type: "localization", Valuer: func(value interface{}, ctx *qor.Context) interface{} {
-			var languageCodes []string
-			var db = ctx.GetDB()
-			var scope = db.NewScope(value)
-			db.New().Set("l10n:mode", "unscoped").Model(res.Value).Where(fmt.Sprintf("%v = ?", scope.PrimaryKey()), scope.PrimaryKeyValue()).Pluck("language_code", &languageCodes)
-			return utils.SliceUniq(languageCodes)
+			statuses, _ := GetLocalizationStatuses(ctx.GetDB(), value, AvailableLocales(ctx))
+			return statuses
 		}})
 
 		res.OverrideIndexAttrs(func() {
@@ -123,16 +334,16 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 			}
 
 			if hasLocalization {
-				res.IndexAttrs(res.IndexAttrs(), "-LanguageCode")
+				res.IndexAttrs(res.IndexAttrs(), "-LanguageCode", "-L10nHidden", "-L10nPlaceholder")
 			} else {
-				res.IndexAttrs(res.IndexAttrs(), "-LanguageCode", "Localization")
+				res.IndexAttrs(res.IndexAttrs(), "-LanguageCode", "-L10nHidden", "-L10nPlaceholder", "Localization")
 			}
 		})
 		res.OverrideShowAttrs(func() {
-			res.ShowAttrs(res.ShowAttrs(), "-LanguageCode", "-Localization")
+			res.ShowAttrs(res.ShowAttrs(), "-LanguageCode", "-Localization", "-L10nHidden", "-L10nPlaceholder")
 		})
-		res.NewAttrs(res.NewAttrs(), "-LanguageCode", "-Localization")
-		res.EditAttrs(res.EditAttrs(), "-LanguageCode", "-Localization")
+		res.NewAttrs(res.NewAttrs(), "-LanguageCode", "-Localization", "-L10nHidden", "-L10nPlaceholder")
+		res.EditAttrs(res.EditAttrs(), "-LanguageCode", "-Localization", "-L10nHidden", "-L10nPlaceholder")
 
 		// Set meta permissions
 		for _, field := range Admin.DB.NewScope(res.Value).Fields() {
@@ -140,9 +351,9 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 				if meta := res.GetMeta(field.Name); meta != nil {
 					permission := meta.Meta.Permission
 					if permission == nil {
-						permission = roles.Allow(roles.CRUD, "global_admin").Allow(roles.Read, "locale_reader")
+						permission = roles.Allow(roles.CRUD, config.GlobalAdminRoleName).Allow(roles.Read, config.ReaderRoleName)
 					} else {
-						permission = permission.Allow(roles.CRUD, "global_admin").Allow(roles.Read, "locale_reader")
+						permission = permission.Allow(roles.CRUD, config.GlobalAdminRoleName).Allow(roles.Read, config.ReaderRoleName)
 					}
 
 					meta.SetPermission(permission)
@@ -152,11 +363,12 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 
 		// Roles
 		role := res.Permission.Role
-		if _, ok := role.Get("global_admin"); !ok {
-			role.Register("global_admin", func(req *http.Request, currentUser interface{}) bool {
-				if getLocaleFromContext(&qor.Context{Request: req}) == Global {
-					for _, locale := range getEditableLocales(req, currentUser) {
-						if locale == Global {
+		if _, ok := role.Get(config.GlobalAdminRoleName); !ok {
+			role.Register(config.GlobalAdminRoleName, func(req *http.Request, currentUser interface{}) bool {
+				context := &qor.Context{Request: req, CurrentUser: currentUser, DB: Admin.DB}
+				if getLocaleFromContext(context) == config.GlobalLocale {
+					for _, locale := range EditableLocales(context) {
+						if locale == config.GlobalLocale {
 							return true
 						}
 					}
@@ -165,10 +377,11 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 			})
 		}
 
-		if _, ok := role.Get("locale_admin"); !ok {
-			role.Register("locale_admin", func(req *http.Request, currentUser interface{}) bool {
-				currentLocale := getLocaleFromContext(&qor.Context{Request: req})
-				for _, locale := range getEditableLocales(req, currentUser) {
+		if _, ok := role.Get(config.AdminRoleName); !ok {
+			role.Register(config.AdminRoleName, func(req *http.Request, currentUser interface{}) bool {
+				context := &qor.Context{Request: req, CurrentUser: currentUser, DB: Admin.DB}
+				currentLocale := getLocaleFromContext(context)
+				for _, locale := range EditableLocales(context) {
 					if locale == currentLocale {
 						return true
 					}
@@ -177,10 +390,11 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 			})
 		}
 
-		if _, ok := role.Get("locale_reader"); !ok {
-			role.Register("locale_reader", func(req *http.Request, currentUser interface{}) bool {
-				currentLocale := getLocaleFromContext(&qor.Context{Request: req})
-				for _, locale := range getAvailableLocales(req, currentUser) {
+		if _, ok := role.Get(config.ReaderRoleName); !ok {
+			role.Register(config.ReaderRoleName, func(req *http.Request, currentUser interface{}) bool {
+				context := &qor.Context{Request: req, CurrentUser: currentUser, DB: Admin.DB}
+				currentLocale := getLocaleFromContext(context)
+				for _, locale := range AvailableLocales(context) {
 					if locale == currentLocale {
 						return true
 					}
@@ -197,8 +411,8 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 			Name: "l10n_set_locale",
 			Handler: func(context *admin.Context, middleware *admin.Middleware) {
 				db := context.GetDB().Set("l10n:locale", getLocaleFromContext(context.Context))
-				if mode := context.Request.URL.Query().Get("locale_mode"); mode != "" {
-					db = db.Set("l10n:mode", mode)
+				if mode := Mode(context.Request.URL.Query().Get("locale_mode")); mode.IsValid() {
+					db = WithMode(db, mode)
 				}
 
 				usingLanguageCodeAsPrimaryKey := false
@@ -223,8 +437,14 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 				}
 
 				if !usingLanguageCodeAsPrimaryKey {
+					column := config.LanguageColumn
+					if res := context.Resource; res != nil {
+						column = LanguageColumn(db.NewScope(res.Value))
+					}
+
+					primaryKeyRegexp := regexp.MustCompile(fmt.Sprintf(`primary_key\[.+_%v\]`, regexp.QuoteMeta(column)))
 					for key, values := range context.Request.URL.Query() {
-						if regexp.MustCompile(`primary_key\[.+_language_code\]`).MatchString(key) {
+						if primaryKeyRegexp.MatchString(key) {
 							if len(values) > 0 {
 								db = db.Set("l10n:locale", values[0])
 
@@ -241,7 +461,12 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 				}
 
 				if context.Request.URL.Query().Get("sorting") != "" {
-					db = db.Set("l10n:mode", "locale")
+					db = WithMode(db, ModeLocale)
+				}
+
+				// load localization statuses of all records on the index page in one query, other queries like actions' don't need them
+				if res := context.Resource; res != nil && context.Request.Method == "GET" && res.GetPrimaryValue(context.Request) == "" && !strings.HasSuffix(context.Request.URL.Path, "/new") {
+					db = WithLocalizationCache(db)
 				}
 				context.SetDB(db)
 
@@ -250,31 +475,71 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 		})
 
 		// FunMap
+		Admin.RegisterFuncMap("localization_url", func(context *admin.Context, value interface{}, locale string) string {
+			return localizationURL(context, value, locale)
+		})
+
 		Admin.RegisterFuncMap("current_locale", func(context admin.Context) string {
 			return getLocaleFromContext(context.Context)
 		})
 
+		Admin.RegisterFuncMap("translation_source", func(context admin.Context) *TranslationSource {
+			currentLocale := getLocaleFromContext(context.Context)
+			if currentLocale == config.GlobalLocale || context.Result == nil {
+				return nil
+			}
+
+			var locales []string
+			for _, locale := range AvailableLocales(context.Context) {
+				if locale != currentLocale {
+					locales = append(locales, locale)
+				}
+			}
+
+			sourceLocale := context.Request.URL.Query().Get("translation_source")
+			if !includeLocale(locales, sourceLocale) {
+				sourceLocale = config.GlobalLocale
+			}
+
+			source, err := GetTranslationSource(context.GetDB(), context.Result, sourceLocale)
+			if err != nil || source == nil {
+				return nil
+			}
+			source.Locales = locales
+			return source
+		})
+
+		Admin.RegisterFuncMap("hideable_resource", func(context admin.Context) bool {
+			if context.Resource == nil {
+				return false
+			}
+			_, ok := context.Resource.Value.(hideableInterface)
+			return ok
+		})
+
+		registerLocaleFuncMaps(Admin)
+
 		Admin.RegisterFuncMap("global_locale", func() string {
-			return Global
+			return config.GlobalLocale
 		})
 
 		Admin.RegisterFuncMap("viewable_locales", func(context admin.Context) []string {
-			return getAvailableLocales(context.Request, context.CurrentUser)
+			return AvailableLocales(context.Context)
 		})
 
 		Admin.RegisterFuncMap("editable_locales", func(context admin.Context) []string {
-			return getEditableLocales(context.Request, context.CurrentUser)
+			return EditableLocales(context.Context)
 		})
 
 		Admin.RegisterFuncMap("createable_locales", func(context admin.Context) []string {
-			editableLocales := getEditableLocales(context.Request, context.CurrentUser)
+			editableLocales := EditableLocales(context.Context)
 			if _, ok := context.Resource.Value.(localeCreatableInterface); ok {
 				return editableLocales
 			}
 
 			for _, locale := range editableLocales {
-				if locale == Global {
-					return []string{Global}
+				if locale == config.GlobalLocale {
+					return []string{config.GlobalLocale}
 				}
 			}
 			return []string{}
@@ -282,66 +547,78 @@ func (l *Locale) ConfigureQorResource(res resource.Resourcer) {
 
 		if res.GetAction("Localize") == nil {
 			argumentResource := Admin.NewResource(&LocalizeActionArgument{})
-			argumentResource.Meta(&admin.Meta{
-				Name: "From",
-				Type: "select_one",
-				Valuer: func(_ interface{}, context *qor.Context) interface{} {
-					return Global
-				},
-				Collection: func(value interface{}, context *qor.Context) (results [][]string) {
-					for _, locale := range getAvailableLocales(context.Request, context.CurrentUser) {
-						results = append(results, []string{locale, locale})
-					}
-					return
-				},
-			})
-			argumentResource.Meta(&admin.Meta{
-				Name: "To",
-				Type: "select_many",
-				Valuer: func(_ interface{}, context *qor.Context) interface{} {
-					return []string{getLocaleFromContext(context)}
-				},
-				Collection: func(value interface{}, context *qor.Context) (results [][]string) {
-					for _, locale := range getEditableLocales(context.Request, context.CurrentUser) {
-						results = append(results, []string{locale, locale})
-					}
-					return
-				},
-			})
+			ConfigureLocalizeArgumentResource(argumentResource, res)
 
 			res.Action(&admin.Action{
 				Name: "Localize",
 				Handler: func(argument *admin.ActionArgument) error {
-					var (
-						db        = argument.Context.GetDB()
-						arg       = argument.Argument.(*LocalizeActionArgument)
-						results   = res.NewSlice()
-						sqls      []string
-						sqlParams []interface{}
-					)
-
-					for _, primaryValue := range argument.PrimaryValues {
-						primaryQuerySQL, primaryParams := res.ToPrimaryQueryParams(primaryValue, argument.Context.Context)
-						sqls = append(sqls, primaryQuerySQL)
-						sqlParams = append(sqlParams, primaryParams...)
+					arg := argument.Argument.(*LocalizeActionArgument)
+					if err := validateLocalizeArgument(argument.Context.Context, arg); err != nil {
+						return err
 					}
 
-					db.Set("l10n:locale", arg.From).Where(strings.Join(sqls, " OR "), sqlParams...).Find(results)
-
-					reflectResults := reflect.Indirect(reflect.ValueOf(results))
-					for i := 0; i < reflectResults.Len(); i++ {
-						for _, to := range arg.To {
-							if err := db.Set("l10n:localize_to", to).Unscoped().Save(reflectResults.Index(i).Interface()).Error; err != nil {
-								return err
-							}
-						}
+					// only records that have been localized in the source locale could be copied from it
+					sourceMode := ModeLocale
+					if arg.From == config.GlobalLocale {
+						sourceMode = ModeGlobal
 					}
-					return nil
+
+					return runRecordsAction(res, argument, "Localize", WithMode(WithLocale(argument.Context.GetDB(), arg.From), sourceMode), arg.To, func(tx *gorm.DB, record interface{}) ([]LocalizeResult, error) {
+						return Localize(WithLocalizeOptions(tx, LocalizeOptions{SkipExisting: arg.SkipExisting, Fields: arg.Fields}), record, arg.From, arg.To...)
+					}, func(summary LocalizeSummary) string {
+						return fmt.Sprintf("Localized: %v", summary)
+					})
 				},
 				Modes:      []string{"index", "menu_item"},
 				Permission: roles.Allow(roles.CRUD, roles.Anyone),
 				Resource:   argumentResource,
 			})
 		}
+
+		if _, ok := res.Value.(hideableInterface); ok {
+			for _, hidden := range []bool{true, false} {
+				hidden := hidden
+				name := "Hide in locale"
+				if !hidden {
+					name = "Unhide"
+				}
+
+				if res.GetAction(name) != nil {
+					continue
+				}
+
+				res.Action(&admin.Action{
+					Name: name,
+					Handler: func(argument *admin.ActionArgument) error {
+						locale := getLocaleFromContext(argument.Context.Context)
+						if locale == config.GlobalLocale {
+							return fmt.Errorf("locale %v is not editable", locale)
+						}
+
+						if err := ValidateEditableLocales(argument.Context.Context, []string{locale}); err != nil {
+							return err
+						}
+
+						status := LocalizeHidden
+						if !hidden {
+							status = LocalizeUnhidden
+						}
+
+						// hidden records are only reachable from their global records
+						return runRecordsAction(res, argument, name, WithMode(argument.Context.GetDB(), ModeGlobal), []string{locale}, func(tx *gorm.DB, record interface{}) ([]LocalizeResult, error) {
+							return setHidden(tx, record, hidden, locale)
+						}, func(summary LocalizeSummary) string {
+							return fmt.Sprintf("%v %v, %v skipped in %v", summary[status], status, summary[LocalizeSkipped], locale)
+						})
+					},
+					Visible: func(record interface{}, context *admin.Context) bool {
+						locale := getLocaleFromContext(context.Context)
+						return locale != config.GlobalLocale && includeLocale(EditableLocales(context.Context), locale)
+					},
+					Modes:      []string{"index", "menu_item"},
+					Permission: roles.Allow(roles.CRUD, roles.Anyone),
+				})
+			}
+		}
 	}
 }
@@ -0,0 +1,129 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestCustomLanguageColumn(t *testing.T) {
+	if column := l10n.LanguageColumn(dbGlobal.NewScope(&Page{})); column != "locale" {
+		t.Errorf("language column should be derived from the field's column tag, but got %v", column)
+	}
+
+	db := dbGlobal.New()
+	l10n.RegisterCallbacks(db, &l10n.Config{LanguageColumn: "lang"})
+	if column := l10n.LanguageColumn(db.NewScope(&Product{})); column != "language_code" {
+		t.Errorf("configured language column should not change columns of localizable models, but got %v", column)
+	}
+
+	if column := l10n.LanguageColumn(dbGlobal.NewScope(&Product{})); column != "language_code" {
+		t.Errorf("language column of other DBs should not be changed, but got %v", column)
+	}
+
+	page := Page{Title: "global"}
+	dbGlobal.Create(&page)
+	if page.LanguageCode != l10n.Global {
+		t.Errorf("should save global record, but got %#v", page)
+	}
+
+	var reversed []Page
+	dbCN.Set("l10n:mode", "reverse").Where("id = ?", page.ID).Find(&reversed)
+	if len(reversed) != 1 {
+		t.Errorf("global record should be listed as unlocalized, but got %#v", reversed)
+	}
+
+	page.Title = "中文"
+	dbCN.Save(&page)
+
+	var pageCN Page
+	if dbCN.Set("l10n:mode", "locale").First(&pageCN, page.ID).RecordNotFound() || pageCN.LanguageCode != "zh" || pageCN.Title != "中文" {
+		t.Errorf("should save localized record, but got %#v", pageCN)
+	}
+
+	var pageEN Page
+	dbEN.First(&pageEN, page.ID)
+	if pageEN.LanguageCode != l10n.Global || pageEN.Title != "global" {
+		t.Errorf("should fall back to global record, but got %#v", pageEN)
+	}
+
+	var count int
+	dbGlobal.Set("l10n:mode", "unscoped").Model(&Page{}).Where("id = ?", page.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("should have 2 records in all locales, but got %v", count)
+	}
+
+	reports, err := l10n.Coverage(dbCN, &Page{})
+	checkHasErr(t, err)
+	if len(reports) != 1 || reports[0].Localized != 1 || reports[0].Missing != 0 {
+		t.Errorf("should report coverage with the language column, but got %#v", reports)
+	}
+
+	results, err := l10n.Unlocalize(dbGlobal, &page, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeDeleted {
+		t.Errorf("should unlocalize record, but got %#v", results)
+	}
+}
+
+func TestCustomLocaleType(t *testing.T) {
+	if column := l10n.LanguageColumn(dbGlobal.NewScope(&Menu{})); column != "locale_id" {
+		t.Errorf("language column should be derived from the field's column tag, but got %v", column)
+	}
+
+	menu := Menu{Title: "global"}
+	dbGlobal.Create(&menu)
+	if !menu.IsGlobal() {
+		t.Errorf("should save global record, but got %#v", menu)
+	}
+
+	var reversed []Menu
+	dbCN.Set("l10n:mode", "reverse").Where("id = ?", menu.ID).Find(&reversed)
+	if len(reversed) != 1 {
+		t.Errorf("global record should be listed as unlocalized, but got %#v", reversed)
+	}
+
+	menu.Title = "中文"
+	dbCN.Save(&menu)
+
+	var menuCN Menu
+	if dbCN.Set("l10n:mode", "locale").First(&menuCN, menu.ID).RecordNotFound() || menuCN.LanguageCode != "zh" || menuCN.Title != "中文" {
+		t.Errorf("should save localized record, but got %#v", menuCN)
+	}
+
+	var menuEN Menu
+	dbEN.First(&menuEN, menu.ID)
+	if !menuEN.IsGlobal() || menuEN.Title != "global" {
+		t.Errorf("should fall back to global record, but got %#v", menuEN)
+	}
+
+	var count int
+	dbGlobal.Table("menus").Where("id = ? AND locale_id IN (?)", menu.ID, []int{1, 2}).Count(&count)
+	if count != 2 {
+		t.Errorf("should save locales as ids, but got %v records", count)
+	}
+
+	reports, err := l10n.Coverage(dbCN, &Menu{})
+	checkHasErr(t, err)
+	if len(reports) != 1 || reports[0].Localized != 1 || reports[0].Missing != 0 {
+		t.Errorf("should report coverage with the custom locale type, but got %#v", reports)
+	}
+
+	statuses, err := l10n.GetLocalizationStatuses(dbGlobal, &menu, []string{"zh"})
+	checkHasErr(t, err)
+	var localizedCN bool
+	for _, status := range statuses {
+		if status.Locale == "zh" && status.State == l10n.LocalizationLocalized {
+			localizedCN = true
+		}
+	}
+	if !localizedCN {
+		t.Errorf("should report localization status of zh, but got %#v", statuses)
+	}
+
+	results, err := l10n.Unlocalize(dbGlobal, &menu, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeDeleted {
+		t.Errorf("should unlocalize record, but got %#v", results)
+	}
+}
@@ -0,0 +1,201 @@
+package l10n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LocaleInfo information of a registered locale
+type LocaleInfo struct {
+	// Code canonical BCP 47 language tag, e.g: zh-CN
+	Code string
+	// Name display name in English, e.g: Chinese (Simplified)
+	Name string
+	// NativeName display name in the locale itself, e.g: 简体中文
+	NativeName string
+	// Direction text direction, "ltr" or "rtl", default is "ltr"
+	Direction string
+	// Parent locale that the locale derives from, used as fallback chain when `FallbackToParentLocales` is enabled, e.g: zh-HK -> zh-TW
+	Parent string
+}
+
+// IsRTL return locale is written from right to left or not
+func (info LocaleInfo) IsRTL() bool {
+	return strings.EqualFold(info.Direction, "rtl")
+}
+
+// DisplayName return name of the locale used in UI, "Name (NativeName)" if both are set
+func (info LocaleInfo) DisplayName() string {
+	switch {
+	case info.Name != "" && info.NativeName != "" && info.Name != info.NativeName:
+		return fmt.Sprintf("%v (%v)", info.Name, info.NativeName)
+	case info.Name != "":
+		return info.Name
+	case info.NativeName != "":
+		return info.NativeName
+	}
+	return info.Code
+}
+
+// LocaleRegistry registered locales, keyed by code
+type LocaleRegistry map[string]LocaleInfo
+
+// Locales registry of supported locales, if any locale is registered, records could only be saved in registered locales or the global locale, and case/underscore variants like `zh_cn` are saved as the registered code `zh-CN`, e.g:
+//
+//	l10n.Locales.Register(
+//	  l10n.LocaleInfo{Code: "zh-CN", Name: "Chinese (Simplified)", NativeName: "简体中文"},
+//	  l10n.LocaleInfo{Code: "ar", Name: "Arabic", NativeName: "العربية", Direction: "rtl"},
+//	)
+var Locales = LocaleRegistry{}
+
+// localesMutex guard registries, so locales could be registered while querying
+var localesMutex sync.RWMutex
+
+// Register register locales, codes are canonicalized
+func (registry LocaleRegistry) Register(infos ...LocaleInfo) {
+	localesMutex.Lock()
+	defer localesMutex.Unlock()
+
+	for _, info := range infos {
+		info.Code = CanonicalLocale(info.Code)
+		if info.Parent != "" {
+			info.Parent = CanonicalLocale(info.Parent)
+		}
+		if info.Direction == "" {
+			info.Direction = "ltr"
+		}
+		registry[info.Code] = info
+	}
+}
+
+// Get find registered locale by code, case and underscore variants of the code are accepted
+func (registry LocaleRegistry) Get(code string) (LocaleInfo, bool) {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+
+	if info, ok := registry[code]; ok {
+		return info, true
+	}
+
+	if info, ok := registry[CanonicalLocale(code)]; ok {
+		return info, true
+	}
+
+	normalized := normalizeLocale(code)
+	for key, info := range registry {
+		if strings.EqualFold(key, normalized) {
+			return info, true
+		}
+	}
+	return LocaleInfo{}, false
+}
+
+// Codes return codes of registered locales, sorted
+func (registry LocaleRegistry) Codes() (codes []string) {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+
+	for code := range registry {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return
+}
+
+// parents return parents of a registered locale, from the nearest one
+func (registry LocaleRegistry) parents(code string) (parents []string) {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+
+	info, ok := registry[code]
+	for ok && info.Parent != "" && !includeLocale(parents, info.Parent) {
+		parents = append(parents, info.Parent)
+		info, ok = registry[info.Parent]
+	}
+	return
+}
+
+// clone return a copy of the registry
+func (registry LocaleRegistry) clone() LocaleRegistry {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+
+	results := LocaleRegistry{}
+	for code, info := range registry {
+		results[code] = info
+	}
+	return results
+}
+
+// CanonicalLocale canonicalize case and separators of a language tag, e.g: zh_cn -> zh-CN, ZH-hant-hk -> zh-Hant-HK
+func CanonicalLocale(locale string) string {
+	subtags := strings.Split(normalizeLocale(locale), "-")
+	for idx, subtag := range subtags {
+		switch {
+		case idx == 0:
+			subtags[idx] = strings.ToLower(subtag)
+		case len(subtag) == 2:
+			subtags[idx] = strings.ToUpper(subtag)
+		case len(subtag) == 4:
+			subtags[idx] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		default:
+			subtags[idx] = strings.ToLower(subtag)
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+// LocaleName return display name of locale registered in `Locales`, return the code if it is not registered
+func LocaleName(locale string) string {
+	return Locales.Name(locale)
+}
+
+// LocaleDirection return text direction of locale registered in `Locales`, "ltr" if it is not registered
+func LocaleDirection(locale string) string {
+	return Locales.Direction(locale)
+}
+
+// Name return display name of locale, return the code if it is not registered
+func (registry LocaleRegistry) Name(locale string) string {
+	if info, ok := registry.Get(locale); ok {
+		return info.DisplayName()
+	}
+	return locale
+}
+
+// Direction return text direction of locale, "ltr" if it is not registered
+func (registry LocaleRegistry) Direction(locale string) string {
+	if info, ok := registry.Get(locale); ok && info.IsRTL() {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// registeredLocale return registered or supported code of locale, return locale itself if not found
+func registeredLocale(config *Config, locale string) string {
+	if locale == config.GlobalLocale || locale == PseudoLocale {
+		return locale
+	}
+
+	if info, ok := config.Locales.Get(locale); ok {
+		return info.Code
+	}
+
+	normalized := normalizeLocale(locale)
+	for _, supportedLocale := range config.SupportedLocales {
+		if strings.EqualFold(supportedLocale, normalized) {
+			return supportedLocale
+		}
+	}
+	return locale
+}
+
+// validateLocale check locale is supported, any locale is valid if no locale is supported or registered
+func validateLocale(config *Config, locale string) error {
+	if locale == PseudoLocale || config.IsSupported(locale) {
+		return nil
+	}
+	return fmt.Errorf("l10n: unknown locale %v", locale)
+}
@@ -0,0 +1,72 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestCanonicalLocale(t *testing.T) {
+	for input, expected := range map[string]string{"zh_cn": "zh-CN", "ZH-hant-hk": "zh-Hant-HK", "en": "en", "es-419": "es-419"} {
+		if locale := l10n.CanonicalLocale(input); locale != expected {
+			t.Errorf("canonical locale of %v should be %v, but got %v", input, expected, locale)
+		}
+	}
+}
+
+func TestLocaleRegistry(t *testing.T) {
+	l10n.Locales.Register(
+		l10n.LocaleInfo{Code: "zh_cn", Name: "Chinese (Simplified)", NativeName: "简体中文"},
+		l10n.LocaleInfo{Code: "zh-HK", Name: "Chinese (Hong Kong)", Parent: "zh-CN"},
+		l10n.LocaleInfo{Code: "ar", Name: "Arabic", NativeName: "العربية", Direction: "rtl"},
+	)
+	defer func() { l10n.Locales = l10n.LocaleRegistry{} }()
+
+	if info, ok := l10n.Locales.Get("ZH_CN"); !ok || info.Code != "zh-CN" {
+		t.Errorf("should find registered locale by variants, but got %#v", info)
+	}
+
+	if l10n.LocaleName("zh-CN") != "Chinese (Simplified) (简体中文)" || l10n.LocaleName("fr") != "fr" {
+		t.Errorf("wrong locale name, got %v", l10n.LocaleName("zh-CN"))
+	}
+
+	if l10n.LocaleDirection("ar") != "rtl" || l10n.LocaleDirection("zh-CN") != "ltr" {
+		t.Errorf("wrong locale direction")
+	}
+
+	product := Product{Code: "LocaleRegistry", Name: "global"}
+	dbGlobal.Create(&product)
+
+	// variants are saved as the registered code
+	product.Name = "中文"
+	if err := dbGlobal.Set("l10n:locale", "zh_cn").Save(&product).Error; err != nil {
+		t.Errorf("should save record in registered locale, but got %v", err)
+	}
+
+	var productCN Product
+	if dbGlobal.Set("l10n:locale", "zh-CN").Set("l10n:mode", "locale").First(&productCN, product.ID).RecordNotFound() || productCN.LanguageCode != "zh-CN" {
+		t.Errorf("should save record with canonical locale, but got %#v", productCN)
+	}
+
+	if err := dbGlobal.Set("l10n:locale", "xx-YY").Save(&product).Error; err == nil {
+		t.Errorf("should not save record in unknown locale")
+	}
+
+	if err := dbGlobal.Set("l10n:locale", "xx-YY").Model(&product).Update("name", "unknown").Error; err == nil {
+		t.Errorf("should not update record in unknown locale")
+	}
+
+	if err := dbGlobal.Model(&product).Update("name", "global updated").Error; err != nil {
+		t.Errorf("should save record in global locale, but got %v", err)
+	}
+
+	// parents of registered locales are used as fallbacks
+	l10n.FallbackToParentLocales = true
+	defer func() { l10n.FallbackToParentLocales = false }()
+
+	var productHK Product
+	dbGlobal.Set("l10n:locale", "zh-HK").First(&productHK, product.ID)
+	if productHK.LanguageCode != "zh-CN" || productHK.Name != "中文" {
+		t.Errorf("should fall back to parent locale, but got %#v", productHK)
+	}
+}
@@ -0,0 +1,256 @@
+package l10n
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+)
+
+// LocalizationState localization state of a record in a locale
+type LocalizationState string
+
+const (
+	// LocalizationLocalized the record has been localized
+	LocalizationLocalized LocalizationState = "localized"
+	// LocalizationStale the record has been localized from an outdated revision of the global record
+	LocalizationStale LocalizationState = "stale"
+	// LocalizationMissing the record hasn't been localized
+	LocalizationMissing LocalizationState = "missing"
+	// LocalizationHidden the record has been hidden in the locale, see `Hideable`
+	LocalizationHidden LocalizationState = "hidden"
+)
+
+// LocalizationStatus localization state of a record in a locale
+type LocalizationStatus struct {
+	Locale string
+	State  LocalizationState
+}
+
+type localizationRow struct {
+	locale   string
+	revision string
+	hidden   bool
+}
+
+// localizationCache cache of language codes and revisions for records, keyed by table name and primary keys
+type localizationCache struct {
+	mutex sync.RWMutex
+	rows  map[string][]localizationRow
+}
+
+// WithLocalizationCache set a cache into DB, when querying localizable records with it, localization statuses of all queried records are loaded in one query, so rendering them for a list won't query for each record
+func WithLocalizationCache(db *gorm.DB) *gorm.DB {
+	return db.Set("l10n:localization_cache", &localizationCache{rows: map[string][]localizationRow{}})
+}
+
+func getLocalizationCache(db *gorm.DB) *localizationCache {
+	if value, ok := db.Get("l10n:localization_cache"); ok {
+		if cache, ok := value.(*localizationCache); ok {
+			return cache
+		}
+	}
+	return nil
+}
+
+func localizationCacheKey(table string, primaryKey []interface{}) string {
+	return fmt.Sprintf("%v/%v", table, primaryKeyString(primaryKey))
+}
+
+// loadLocalizations load language codes and revisions for records with primary keys, and save them into cache
+func loadLocalizations(scope *gorm.Scope, cache *localizationCache, primaryKeys [][]interface{}) error {
+	var primaryStructFields []*gorm.StructField
+	for _, field := range scope.GetModelStruct().PrimaryFields {
+		if field.Name != "LanguageCode" {
+			primaryStructFields = append(primaryStructFields, field)
+		}
+	}
+
+	if len(primaryStructFields) == 0 || len(primaryKeys) == 0 {
+		return nil
+	}
+
+	var (
+		table        = scope.TableName()
+		db           = newDB(scope.NewDB())
+		results      = map[string][]localizationRow{}
+		hasRevision  = isRevisionTracked(scope)
+		revisionExpr = "''"
+		hiddenExpr   = "0"
+	)
+
+	for _, primaryKey := range primaryKeys {
+		results[localizationCacheKey(table, primaryKey)] = nil
+	}
+
+	if IsTranslatable(scope) {
+		for _, primaryKey := range primaryKeys {
+			key := localizationCacheKey(table, primaryKey)
+			results[key] = append(results[key], localizationRow{locale: getConfig(scope).GlobalLocale})
+		}
+		table = TranslationTableName(scope)
+		db = db.Table(table)
+	} else {
+		db = db.Table(table)
+		if scope.HasColumn("DeletedAt") {
+			db = db.Where("deleted_at IS NULL")
+		}
+	}
+
+	if hasRevision {
+		revisionExpr = "COALESCE(l10n_revision, '')"
+	}
+
+	if isHideable(scope) && !IsTranslatable(scope) {
+		hiddenExpr = "CASE WHEN l10n_hidden THEN 1 ELSE 0 END"
+	}
+
+	var quotedPrimaryKeys = quotedPrimaryKeys(scope)
+	condition, primaryValues := primaryKeysCondition("", quotedPrimaryKeys, primaryKeys)
+	rows, err := db.Select(fmt.Sprintf("%v, %v, %v, %v", strings.Join(quotedPrimaryKeys, ", "), scope.Quote(LanguageColumn(scope)), revisionExpr, hiddenExpr)).Where(condition, primaryValues...).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			primaryKey []interface{}
+			dests      []interface{}
+			locale     = reflect.New(reflect.TypeOf(""))
+			row        localizationRow
+		)
+
+		for _, field := range primaryStructFields {
+			dests = append(dests, reflect.New(field.Struct.Type).Interface())
+		}
+
+		// scan locales of custom types with the field's type
+		if typ := languageType(scope); typ != nil {
+			locale = reflect.New(typ)
+		}
+
+		var hidden int
+		if err := rows.Scan(append(dests, locale.Interface(), &row.revision, &hidden)...); err != nil {
+			return err
+		}
+		row.locale = locale.Elem().String()
+		row.hidden = hidden == 1
+
+		for _, dest := range dests {
+			primaryKey = append(primaryKey, reflect.ValueOf(dest).Elem().Interface())
+		}
+
+		key := localizationCacheKey(scope.TableName(), primaryKey)
+		results[key] = append(results[key], row)
+	}
+
+	cache.mutex.Lock()
+	for key, values := range results {
+		cache.rows[key] = values
+	}
+	cache.mutex.Unlock()
+	return rows.Err()
+}
+
+// cacheLocalizations load localization statuses for all queried records if DB has a localization cache
+func cacheLocalizations(scope *gorm.Scope) {
+	cache := getLocalizationCache(scope.DB())
+	if cache == nil {
+		return
+	}
+
+	var (
+		primaryKeys [][]interface{}
+		values      = scope.IndirectValue()
+	)
+
+	collect := func(value reflect.Value) {
+		if value.CanAddr() {
+			if primaryKey, ok := primaryKeyValues(scope.New(value.Addr().Interface())); ok {
+				primaryKeys = append(primaryKeys, primaryKey)
+			}
+		}
+	}
+
+	if values.Kind() == reflect.Slice {
+		for i := 0; i < values.Len(); i++ {
+			collect(reflect.Indirect(values.Index(i)))
+		}
+	} else {
+		collect(values)
+	}
+
+	scope.Err(loadLocalizations(scope, cache, primaryKeys))
+}
+
+// GetLocalizationStatuses return localization statuses of record in locales, and in other locales that the record has been localized into, use cached statuses if DB is set with `WithLocalizationCache`
+func GetLocalizationStatuses(db *gorm.DB, record interface{}, locales []string) (statuses []LocalizationStatus, err error) {
+	var (
+		scope      = db.NewScope(record)
+		primaryKey []interface{}
+		cache      = getLocalizationCache(db)
+	)
+
+	for _, field := range primaryFields(scope) {
+		primaryKey = append(primaryKey, field.Field.Interface())
+	}
+
+	if len(primaryKey) == 0 {
+		return nil, fmt.Errorf("l10n: primary key is required")
+	}
+
+	if cache == nil {
+		cache = &localizationCache{rows: map[string][]localizationRow{}}
+	}
+
+	key := localizationCacheKey(scope.TableName(), primaryKey)
+	cache.mutex.RLock()
+	rows, ok := cache.rows[key]
+	cache.mutex.RUnlock()
+
+	if !ok {
+		if err = loadLocalizations(scope, cache, [][]interface{}{primaryKey}); err != nil {
+			return
+		}
+		cache.mutex.RLock()
+		rows = cache.rows[key]
+		cache.mutex.RUnlock()
+	}
+
+	var (
+		globalRevision string
+		global         = getConfig(scope).GlobalLocale
+		localized      = map[string]string{}
+		hidden         = map[string]bool{}
+		allLocales     = append([]string{}, locales...)
+	)
+
+	for _, row := range rows {
+		if row.locale == global {
+			globalRevision = row.revision
+		}
+
+		if _, ok := localized[row.locale]; !ok && !includeLocale(allLocales, row.locale) {
+			allLocales = append(allLocales, row.locale)
+		}
+		localized[row.locale] = row.revision
+		hidden[row.locale] = row.hidden
+	}
+
+	for _, locale := range allLocales {
+		status := LocalizationStatus{Locale: locale, State: LocalizationMissing}
+		if revision, ok := localized[locale]; ok {
+			status.State = LocalizationLocalized
+			if hidden[locale] {
+				status.State = LocalizationHidden
+			} else if locale != global && isRevisionTracked(scope) && revision != globalRevision {
+				status.State = LocalizationStale
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/qor/l10n"
+)
+
+func TestGetLocalizationStatuses(t *testing.T) {
+	post := Post{Code: "LocalizationStatus", Title: "title"}
+	dbGlobal.Create(&post)
+	dbGlobal.Set("l10n:locale", "zh").Create(&post)
+	dbGlobal.Set("l10n:locale", "ja").Create(&post)
+	dbGlobal.Model(&post).Update("title", "new title")
+	dbGlobal.Set("l10n:locale", "ja").Model(&post).Update("title", "ja title")
+
+	db := l10n.WithLocalizationCache(dbGlobal)
+	var posts []Post
+	db.Set("l10n:mode", "global").Where("code = ?", "LocalizationStatus").Find(&posts)
+
+	// statuses are loaded when querying, so won't query again
+	var queries int
+	db.Callback().RowQuery().Before("gorm:row_query").Register("test:count_row_query", func(scope *gorm.Scope) {
+		queries++
+	})
+	defer db.Callback().RowQuery().Remove("test:count_row_query")
+
+	statuses, err := l10n.GetLocalizationStatuses(db, &posts[0], []string{l10n.Global, "zh", "ja", "de"})
+	checkHasErr(t, err)
+	if queries != 0 {
+		t.Errorf("should use statuses loaded when querying, but got %v queries", queries)
+	}
+
+	l10n.GetLocalizationStatuses(dbGlobal, &posts[0], []string{l10n.Global})
+	if queries != 1 {
+		t.Errorf("should load statuses without cache, but got %v queries", queries)
+	}
+
+	expected := map[string]l10n.LocalizationState{l10n.Global: l10n.LocalizationLocalized, "zh": l10n.LocalizationStale, "ja": l10n.LocalizationLocalized, "de": l10n.LocalizationMissing}
+	if len(statuses) != len(expected) {
+		t.Errorf("should return statuses for each locale, but got %#v", statuses)
+	}
+
+	for _, status := range statuses {
+		if expected[status.Locale] != status.State {
+			t.Errorf("%v should be %v, but got %v", status.Locale, expected[status.Locale], status.State)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package l10n
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// LocalizeStatus status of localizing a record into a locale
+type LocalizeStatus string
+
+const (
+	// LocalizeCreated the record has been localized into the locale
+	LocalizeCreated LocalizeStatus = "created"
+	// LocalizeOverwritten the existing localized record has been overwritten
+	LocalizeOverwritten LocalizeStatus = "overwritten"
+	// LocalizeSkipped the locale is skipped, e.g: the record has been localized and existing records are skipped
+	LocalizeSkipped LocalizeStatus = "skipped"
+	// LocalizeDeleted the localized record has been deleted
+	LocalizeDeleted LocalizeStatus = "deleted"
+	// LocalizeHidden the record has been hidden in the locale
+	LocalizeHidden LocalizeStatus = "hidden"
+	// LocalizeUnhidden the record has been unhidden in the locale
+	LocalizeUnhidden LocalizeStatus = "unhidden"
+	// LocalizeFailed failed to localize the record into the locale
+	LocalizeFailed LocalizeStatus = "failed"
+)
+
+// LocalizeOptions options for `Localize`, set with `WithLocalizeOptions`
+type LocalizeOptions struct {
+	// SkipExisting skip locales that the record has been localized into, existing localized records are overwritten by default
+	SkipExisting bool
+	// Fields names of translatable fields to copy from the source locale, all translatable fields are copied if blank, other fields keep the localized record's or global record's values
+	Fields []string
+}
+
+// LocalizeResult result of localizing a record into a locale
+type LocalizeResult struct {
+	Locale string
+	Status LocalizeStatus
+	Err    error
+}
+
+// WithLocalizeOptions set options used by `Localize`
+func WithLocalizeOptions(db *gorm.DB, options LocalizeOptions) *gorm.DB {
+	return db.Set("l10n:localize_options", options)
+}
+
+func getLocalizeOptions(db *gorm.DB) LocalizeOptions {
+	if value, ok := db.Get("l10n:localize_options"); ok {
+		if options, ok := value.(LocalizeOptions); ok {
+			return options
+		}
+	}
+	return LocalizeOptions{}
+}
+
+// localizeFields return fields that are copied when localizing records
+func localizeFields(scope *gorm.Scope) []*gorm.StructField {
+	if IsTranslatable(scope) {
+		return translateFields(scope)
+	}
+	return translatableFields(scope)
+}
+
+// TranslatableFieldNames return names of fields that could be copied when localizing records
+func TranslatableFieldNames(scope *gorm.Scope) (names []string) {
+	for _, field := range localizeFields(scope) {
+		names = append(names, field.Name)
+	}
+	return
+}
+
+// transaction run fc in a transaction, reuse DB's transaction if it is already in one
+func transaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	if _, ok := db.CommonDB().(*sql.Tx); ok {
+		return fc(db)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := fc(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// primaryKeyCondition return condition to find the record in any locale with its primary keys
+func primaryKeyCondition(scope *gorm.Scope) (string, []interface{}, error) {
+	var (
+		conditions []string
+		values     []interface{}
+	)
+
+	for _, field := range primaryFields(scope) {
+		if isBlankPrimaryKey(field) {
+			return "", nil, errors.New("l10n: record's primary key is required")
+		}
+		conditions = append(conditions, fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(field.DBName)))
+		values = append(values, field.Field.Interface())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, errors.New("l10n: record's primary key is required")
+	}
+	return strings.Join(conditions, " AND "), values, nil
+}
+
+// findLocalizedRecord find the record in locale, records hidden in the locale are included, return nil if not found
+func findLocalizedRecord(db *gorm.DB, record interface{}, locale string) (interface{}, error) {
+	scope := db.NewScope(record)
+	condition, primaryKeys, err := primaryKeyCondition(scope)
+	if err != nil {
+		return nil, err
+	}
+	return findRecordInLocale(db, scope, locale, condition, primaryKeys...)
+}
+
+// findRecordInLocale find the record matched with condition in locale, records hidden in the locale are included, return nil if not found
+func findRecordInLocale(db *gorm.DB, scope *gorm.Scope, locale string, condition string, values ...interface{}) (interface{}, error) {
+	var (
+		result  = reflect.New(scope.GetModelStruct().ModelType).Interface()
+		queryDB = WithLocale(db, locale).Where(condition, values...)
+	)
+
+	switch {
+	case GetConfig(db).IsGlobal(locale):
+		queryDB = WithMode(queryDB, ModeGlobal)
+	case IsLocalizable(scope):
+		// locale mode excludes hidden records, which would be treated as not localized and unhidden when saving
+		queryDB = WithMode(queryDB, ModeUnscoped).Where(fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope))), LocaleValue(scope, locale))
+	default:
+		queryDB = WithMode(queryDB, ModeLocale)
+	}
+
+	if err := queryDB.First(result).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Localize copy record from locale `from` into locales `to`, translatable fields are translated with DB's translator if there is, it runs in a transaction, all changes are rolled back if failed to localize the record into any locale, e.g:
+//
+//	results, err := l10n.Localize(l10n.WithLocalizeOptions(db, l10n.LocalizeOptions{SkipExisting: true}), &product, "en-US", "zh-CN", "ja-JP")
+func Localize(db *gorm.DB, record interface{}, from string, to ...string) (results []LocalizeResult, err error) {
+	scope := db.NewScope(record)
+	if !IsLocalizable(scope) && !IsTranslatable(scope) {
+		return nil, fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+	}
+
+	options := getLocalizeOptions(db)
+	fields := localizeFields(scope)
+	if len(options.Fields) > 0 {
+		var selectedFields []*gorm.StructField
+		for _, name := range options.Fields {
+			var found bool
+			for _, field := range fields {
+				if field.Name == name || field.DBName == name {
+					selectedFields = append(selectedFields, field)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("l10n: field %v is not translatable", name)
+			}
+		}
+		fields = selectedFields
+	}
+
+	err = transaction(db, func(tx *gorm.DB) error {
+		source, err := findLocalizedRecord(tx, record, from)
+		if err != nil {
+			return err
+		} else if source == nil {
+			return fmt.Errorf("l10n: record is not found in %v", from)
+		}
+
+		globalLocale := GetConfig(tx).GlobalLocale
+		global, err := findLocalizedRecord(tx, record, globalLocale)
+		if err != nil {
+			return err
+		} else if global == nil {
+			return fmt.Errorf("l10n: record is not found in %v", globalLocale)
+		}
+
+		for _, locale := range to {
+			result := LocalizeResult{Locale: locale}
+			result.Status, result.Err = localizeRecord(tx, source, global, from, locale, fields, options)
+			results = append(results, result)
+			if result.Err != nil {
+				return result.Err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func localizeRecord(tx *gorm.DB, source, global interface{}, from, to string, fields []*gorm.StructField, options LocalizeOptions) (LocalizeStatus, error) {
+	if to == from || GetConfig(tx).IsGlobal(to) {
+		return LocalizeSkipped, nil
+	}
+
+	existing, err := findLocalizedRecord(tx, source, to)
+	if err != nil {
+		return LocalizeFailed, err
+	}
+
+	if existing != nil && options.SkipExisting {
+		return LocalizeSkipped, nil
+	}
+
+	// translate a copy, so the source record is kept for other locales
+	translated := reflect.New(reflect.Indirect(reflect.ValueOf(source)).Type())
+	translated.Elem().Set(reflect.Indirect(reflect.ValueOf(source)))
+	if err := TranslateRecord(tx, translated.Interface(), from, to); err != nil {
+		return LocalizeFailed, err
+	}
+
+	// copy fields into the existing localized record, or the global record if it hasn't been localized
+	status, target := LocalizeCreated, reflect.New(reflect.Indirect(reflect.ValueOf(global)).Type())
+	if existing != nil {
+		status = LocalizeOverwritten
+		target.Elem().Set(reflect.Indirect(reflect.ValueOf(existing)))
+	} else {
+		target.Elem().Set(reflect.Indirect(reflect.ValueOf(global)))
+	}
+
+	var (
+		translatedScope = tx.NewScope(translated.Interface())
+		targetScope     = tx.NewScope(target.Interface())
+	)
+
+	for _, structField := range fields {
+		value, _ := translatedScope.FieldByName(structField.Name)
+		if field, ok := targetScope.FieldByName(structField.Name); ok {
+			if err := field.Set(value.Field.Interface()); err != nil {
+				return LocalizeFailed, err
+			}
+		}
+	}
+
+	if err := WithLocale(tx, to).Set("l10n:localize_to", to).Unscoped().Save(target.Interface()).Error; err != nil {
+		return LocalizeFailed, err
+	}
+	return status, nil
+}
+
+// Unlocalize delete record's localized records in locales, it runs in a transaction, all changes are rolled back if failed to delete any of them
+func Unlocalize(db *gorm.DB, record interface{}, locales ...string) (results []LocalizeResult, err error) {
+	scope := db.NewScope(record)
+	if !IsLocalizable(scope) && !IsTranslatable(scope) {
+		return nil, fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+	}
+
+	err = transaction(db, func(tx *gorm.DB) error {
+		for _, locale := range locales {
+			result := LocalizeResult{Locale: locale}
+			result.Status, result.Err = unlocalizeRecord(tx, record, locale)
+			results = append(results, result)
+			if result.Err != nil {
+				return result.Err
+			}
+		}
+		return nil
+	})
+	return
+}
+
+func unlocalizeRecord(tx *gorm.DB, record interface{}, locale string) (LocalizeStatus, error) {
+	if GetConfig(tx).IsGlobal(locale) {
+		return LocalizeFailed, errors.New("l10n: global record can't be unlocalized")
+	}
+
+	scope := tx.NewScope(record)
+	condition, primaryKeys, err := primaryKeyCondition(scope)
+	if err != nil {
+		return LocalizeFailed, err
+	}
+
+	var deleteDB *gorm.DB
+	if IsTranslatable(scope) {
+		// translations are deleted with the record's primary keys
+		deleteDB = WithLocale(tx, locale).Delete(record)
+	} else {
+		deleteDB = WithLocale(tx, locale).Where(condition, primaryKeys...).Delete(reflect.New(scope.GetModelStruct().ModelType).Interface())
+	}
+	if deleteDB.Error != nil {
+		return LocalizeFailed, deleteDB.Error
+	}
+
+	if deleteDB.RowsAffected > 0 {
+		return LocalizeDeleted, nil
+	}
+	return LocalizeSkipped, nil
+}
+
+// LocalizeSummary count of localize results for each status
+type LocalizeSummary map[LocalizeStatus]int
+
+// Add count results
+func (summary LocalizeSummary) Add(results ...LocalizeResult) {
+	for _, result := range results {
+		summary[result.Status]++
+	}
+}
+
+func (summary LocalizeSummary) String() string {
+	return fmt.Sprintf("%v created, %v overwritten, %v skipped, %v failed", summary[LocalizeCreated], summary[LocalizeOverwritten], summary[LocalizeSkipped], summary[LocalizeFailed])
+}
@@ -0,0 +1,107 @@
+package l10n_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+type failingTranslator struct{ locale string }
+
+func (translator failingTranslator) Translate(from, to string, texts []string) ([]string, error) {
+	if to == translator.locale {
+		return nil, errors.New("failed to translate")
+	}
+	return texts, nil
+}
+
+func TestLocalize(t *testing.T) {
+	product := Product{Code: "Localize", Name: "global name", Description: "global description"}
+	dbGlobal.Create(&product)
+
+	results, err := l10n.Localize(dbGlobal, &product, l10n.Global, "zh", "ja")
+	checkHasErr(t, err)
+	if len(results) != 2 || results[0].Status != l10n.LocalizeCreated || results[1].Status != l10n.LocalizeCreated {
+		t.Errorf("should localize record into locales, but got %#v", results)
+	}
+
+	var productCN Product
+	dbCN.Set("l10n:mode", "locale").First(&productCN, product.ID)
+	if productCN.Name != "global name" || productCN.Code != "Localize" {
+		t.Errorf("should copy record into locale, but got %#v", productCN)
+	}
+
+	dbCN.Model(&productCN).Update("name", "中文名")
+
+	results, err = l10n.Localize(l10n.WithLocalizeOptions(dbGlobal, l10n.LocalizeOptions{SkipExisting: true}), &product, l10n.Global, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeSkipped {
+		t.Errorf("should skip existing localized records, but got %#v", results)
+	}
+
+	// chain from a localized source, and copy selected fields only
+	dbGlobal.Model(&product).Update("description", "new global description")
+	results, err = l10n.Localize(l10n.WithLocalizeOptions(dbGlobal, l10n.LocalizeOptions{Fields: []string{"Name"}}), &product, "zh", "ja")
+	checkHasErr(t, err)
+
+	var productJA Product
+	dbGlobal.Set("l10n:locale", "ja").Set("l10n:mode", "locale").First(&productJA, product.ID)
+	if results[0].Status != l10n.LocalizeOverwritten || productJA.Name != "中文名" || productJA.Description != "global description" {
+		t.Errorf("should overwrite selected fields from source locale, but got %#v, %#v", results, productJA)
+	}
+
+	if _, err = l10n.Localize(l10n.WithLocalizeOptions(dbGlobal, l10n.LocalizeOptions{Fields: []string{"Code"}}), &product, l10n.Global, "ja"); err == nil {
+		t.Errorf("should not copy sync fields")
+	}
+
+	// roll back all locales if any failed
+	results, err = l10n.Localize(l10n.WithTranslator(dbGlobal, failingTranslator{locale: "zh-TW"}), &product, l10n.Global, "en", "zh-TW")
+	if err == nil || len(results) != 2 || results[0].Status != l10n.LocalizeCreated || results[1].Status != l10n.LocalizeFailed {
+		t.Errorf("should report failed locale, but got %#v, %v", results, err)
+	}
+
+	summary := l10n.LocalizeSummary{}
+	summary.Add(results...)
+	if summary.String() != "1 created, 0 overwritten, 0 skipped, 1 failed" {
+		t.Errorf("should summarize results, but got %v", summary)
+	}
+
+	if !dbEN.Set("l10n:mode", "locale").First(&Product{}, product.ID).RecordNotFound() {
+		t.Errorf("should roll back localized records if failed")
+	}
+
+	results, err = l10n.Unlocalize(dbGlobal, &product, "zh", "en")
+	checkHasErr(t, err)
+	if len(results) != 2 || results[0].Status != l10n.LocalizeDeleted || results[1].Status != l10n.LocalizeSkipped {
+		t.Errorf("should unlocalize record, but got %#v", results)
+	}
+
+	if !dbCN.Set("l10n:mode", "locale").First(&Product{}, product.ID).RecordNotFound() {
+		t.Errorf("should delete localized record")
+	}
+
+	if _, err = l10n.Unlocalize(dbGlobal, &product, l10n.Global); err == nil {
+		t.Errorf("should not unlocalize global record")
+	}
+}
+
+func TestLocalizeTranslatable(t *testing.T) {
+	article := Article{Code: "LocalizeTranslatable", Title: "global title", Body: "global body"}
+	dbGlobal.Create(&article)
+
+	results, err := l10n.Localize(l10n.WithTranslator(dbGlobal, l10n.DictionaryTranslator{"zh": {"global title": "中文标题"}}), &article, l10n.Global, "zh")
+	checkHasErr(t, err)
+
+	var articleCN Article
+	dbCN.Set("l10n:mode", "locale").First(&articleCN, article.ID)
+	if results[0].Status != l10n.LocalizeCreated || articleCN.Title != "中文标题" || articleCN.Body != "global body" {
+		t.Errorf("should save translation, but got %#v, %#v", results, articleCN)
+	}
+
+	results, err = l10n.Unlocalize(dbGlobal, &article, "zh")
+	checkHasErr(t, err)
+	if results[0].Status != l10n.LocalizeDeleted || !dbCN.Set("l10n:mode", "locale").First(&Article{}, article.ID).RecordNotFound() {
+		t.Errorf("should delete translation, but got %#v", results)
+	}
+}
@@ -0,0 +1,155 @@
+package l10n
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"github.com/jinzhu/gorm"
+	"github.com/qor/admin"
+	"github.com/qor/qor"
+	"github.com/qor/qor/resource"
+	"github.com/qor/qor/utils"
+)
+
+// LocalizedString a string that has values for different locales, saved as JSON, for small models that don't need to localize whole records, e.g:
+//
+//	type Option struct {
+//	  gorm.Model
+//	  Label l10n.LocalizedString `sql:"type:text"`
+//	}
+//
+//	option.Label = l10n.LocalizedString{"en-US": "Size", "zh-CN": "尺码"}
+//	option.Label.Get("zh-CN") // "尺码"
+type LocalizedString map[string]string
+
+// Get return value for locale, if the locale hasn't value, will fall back to its fallback locales of the default configuration, use `Resolve` for DBs with configuration
+func (str LocalizedString) Get(locale string) string {
+	return str.get(GetConfig(nil), locale)
+}
+
+func (str LocalizedString) get(config *Config, locale string) string {
+	if locale == PseudoLocale {
+		return Pseudolocalize(str.get(config, config.GlobalLocale))
+	}
+
+	for _, fallback := range append([]string{locale}, config.FallbackLocales(locale)...) {
+		if value := str[fallback]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Resolve return value for DB's current locale, set with `l10n:locale`, fall back with DB's configuration
+func (str LocalizedString) Resolve(db *gorm.DB) string {
+	config := GetConfig(db)
+	if locale, ok := db.Get("l10n:locale"); ok {
+		if locale, ok := locale.(string); ok && locale != "" {
+			return str.get(config, locale)
+		}
+	}
+	return str.get(config, config.GlobalLocale)
+}
+
+// String return value of the default configuration's global locale
+func (str LocalizedString) String() string {
+	return str.Get(GetConfig(nil).GlobalLocale)
+}
+
+// Scan implements the sql.Scanner interface
+func (str *LocalizedString) Scan(value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		*str = nil
+		return nil
+	case []byte:
+		if len(value) == 0 {
+			*str = nil
+			return nil
+		}
+		return json.Unmarshal(value, str)
+	case string:
+		if value == "" {
+			*str = nil
+			return nil
+		}
+		return json.Unmarshal([]byte(value), str)
+	}
+	return errors.New("unsupported value for LocalizedString")
+}
+
+// Value implements the driver.Valuer interface
+func (str LocalizedString) Value() (driver.Value, error) {
+	if str == nil {
+		return nil, nil
+	}
+
+	results, err := json.Marshal(str)
+	return string(results), err
+}
+
+// ConfigureQorMeta configure localized string meta for Qor Admin, it renders one input for each editable locale
+func (LocalizedString) ConfigureQorMeta(metaor resource.Metaor) {
+	if meta, ok := metaor.(*admin.Meta); ok {
+		meta.Type = "localized_string"
+
+		meta.Setter = func(record interface{}, metaValue *resource.MetaValue, context *qor.Context) {
+			if metaValue.MetaValues == nil {
+				return
+			}
+
+			field := reflect.Indirect(reflect.ValueOf(record)).FieldByName(meta.FieldName)
+			if !field.IsValid() || !field.CanSet() {
+				return
+			}
+
+			// keep values of locales that are not editable by current user
+			str := LocalizedString{}
+			if existing, ok := field.Interface().(LocalizedString); ok {
+				for locale, value := range existing {
+					str[locale] = value
+				}
+			}
+
+			// inputs are named with their locales, e.g: QorResource.Label.zh-CN
+			editableLocales := EditableLocales(context)
+			for _, value := range metaValue.MetaValues.Values {
+				if includeLocale(editableLocales, value.Name) {
+					str[value.Name] = utils.ToString(value.Value)
+				}
+			}
+			field.Set(reflect.ValueOf(str))
+		}
+
+		res, ok := meta.GetBaseResource().(*admin.Resource)
+		if !ok {
+			return
+		}
+
+		Admin := res.GetAdmin()
+		Admin.RegisterViewPath("github.com/qor/l10n/views")
+
+		// the form uses them for models that are not localizable too
+		registerLocaleFuncMaps(Admin)
+
+		Admin.RegisterFuncMap("localized_string_locales", func(context *admin.Context) []string {
+			return EditableLocales(context.Context)
+		})
+
+		Admin.RegisterFuncMap("localized_string_value", func(value interface{}, locale string) string {
+			if str, ok := value.(LocalizedString); ok {
+				return str[locale]
+			}
+			return ""
+		})
+
+		Admin.RegisterFuncMap("localized_string_current_value", func(context *admin.Context, value interface{}) string {
+			if str, ok := value.(LocalizedString); ok {
+				return str.Resolve(WithLocale(context.GetDB(), getLocaleFromContext(context.Context)))
+			}
+			return ""
+		})
+	}
+}
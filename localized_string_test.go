@@ -0,0 +1,39 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestLocalizedString(t *testing.T) {
+	l10n.Fallbacks["zh-HK"] = []string{"zh-TW"}
+	defer delete(l10n.Fallbacks, "zh-HK")
+
+	option := Option{Label: l10n.LocalizedString{l10n.Global: "Size", "zh-TW": "尺寸", "zh": "尺码"}}
+	checkHasErr(t, dbGlobal.Create(&option).Error)
+
+	var result Option
+	checkHasErr(t, dbGlobal.First(&result, option.ID).Error)
+
+	for locale, expected := range map[string]string{l10n.Global: "Size", "zh": "尺码", "zh-HK": "尺寸", "ja": "Size"} {
+		if value := result.Label.Get(locale); value != expected {
+			t.Errorf("label for %v should be %v, but got %v", locale, expected, value)
+		}
+	}
+
+	if value := result.Label.Resolve(dbCN); value != "尺码" {
+		t.Errorf("label for db's locale should be 尺码, but got %v", value)
+	}
+
+	if value := result.Label.String(); value != "Size" {
+		t.Errorf("label's string should be the global value, but got %v", value)
+	}
+
+	var emptyOption Option
+	checkHasErr(t, dbGlobal.Create(&emptyOption).Error)
+	checkHasErr(t, dbGlobal.First(&emptyOption, emptyOption.ID).Error)
+	if emptyOption.Label != nil || emptyOption.Label.Get("zh") != "" {
+		t.Errorf("blank label should be nil")
+	}
+}
@@ -0,0 +1,221 @@
+package l10n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+type contextKey string
+
+const (
+	localeContextKey contextKey = "l10n:locale"
+	dbContextKey     contextKey = "l10n:db"
+)
+
+// Middleware net/http middleware that resolves current request's locale, and saves the locale and a locale scoped DB into request's context, e.g:
+//
+//	middleware := &l10n.Middleware{DB: db, SupportedLocales: []string{"en-US", "zh-CN"}}
+//	http.ListenAndServe(":7000", middleware.Handler(mux))
+//
+// The locale is resolved from URL prefix (/zh-CN/products), query param, cookie and then `Accept-Language` header, matched against supported locales
+type Middleware struct {
+	DB *gorm.DB
+	// SupportedLocales supported locales, default is the global locale and supported locales of DB's configuration
+	SupportedLocales []string
+	// QueryParam query param name used to get locale, default is "locale"
+	QueryParam string
+	// CookieName cookie name used to get locale, default is "locale", the locale will be saved into the cookie if it is set by query param
+	CookieName string
+	// StripURLPrefix remove locale prefix from URL path before passing the request to next handler
+	StripURLPrefix bool
+}
+
+// Handler wrap handler with the middleware
+func (middleware *Middleware) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		locale, prefix, fromQuery := middleware.negotiate(req)
+
+		if fromQuery {
+			http.SetCookie(w, &http.Cookie{Name: middleware.cookieName(), Value: locale, Path: "/"})
+		}
+		// locales are resolved from the cookie and Accept-Language header
+		w.Header().Add("Vary", "Cookie")
+		w.Header().Add("Vary", "Accept-Language")
+
+		if prefix != "" && middleware.StripURLPrefix {
+			req.URL.Path = strings.TrimPrefix(req.URL.Path, "/"+prefix)
+			if req.URL.Path == "" {
+				req.URL.Path = "/"
+			}
+			req.URL.RawPath = ""
+		}
+
+		ctx := context.WithValue(req.Context(), localeContextKey, locale)
+		if middleware.DB != nil {
+			ctx = context.WithValue(ctx, dbContextKey, WithLocale(middleware.DB, locale))
+		}
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// Negotiate resolve locale for the request
+func (middleware *Middleware) Negotiate(req *http.Request) string {
+	locale, _, _ := middleware.negotiate(req)
+	return locale
+}
+
+func (middleware *Middleware) negotiate(req *http.Request) (locale string, prefix string, fromQuery bool) {
+	if paths := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2); paths[0] != "" {
+		if locale, ok := middleware.match(paths[0], false); ok {
+			return locale, paths[0], false
+		}
+	}
+
+	if locale, ok := middleware.match(req.URL.Query().Get(middleware.queryParam()), true); ok {
+		return locale, "", true
+	}
+
+	if cookie, err := req.Cookie(middleware.cookieName()); err == nil {
+		if locale, ok := middleware.match(cookie.Value, true); ok {
+			return locale, "", false
+		}
+	}
+
+	languages := parseAcceptLanguage(req.Header.Get("Accept-Language"))
+	for _, language := range languages {
+		if locale, ok := middleware.match(language, true); ok {
+			return locale, "", false
+		}
+	}
+
+	// match supported locales that have the same language, e.g: zh -> zh-CN
+	for _, language := range languages {
+		base := strings.ToLower(strings.SplitN(normalizeLocale(language), "-", 2)[0])
+		for _, supportedLocale := range middleware.supportedLocales() {
+			if strings.ToLower(strings.SplitN(supportedLocale, "-", 2)[0]) == base {
+				return supportedLocale, "", false
+			}
+		}
+	}
+
+	return middleware.defaultLocale(), "", false
+}
+
+// match find supported locale for str, if withParents is true, will try its parent locales also, e.g: zh-Hant-TW -> zh-Hant -> zh
+func (middleware *Middleware) match(str string, withParents bool) (string, bool) {
+	if str = normalizeLocale(str); str == "" {
+		return "", false
+	}
+
+	candidates := []string{str}
+	if withParents {
+		candidates = append(candidates, parentLocales(GetConfig(middleware.DB).Locales, str)...)
+	}
+
+	for _, candidate := range candidates {
+		for _, supportedLocale := range middleware.supportedLocales() {
+			if strings.EqualFold(candidate, supportedLocale) {
+				return supportedLocale, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (middleware *Middleware) supportedLocales() []string {
+	if len(middleware.SupportedLocales) == 0 {
+		config := GetConfig(middleware.DB)
+		if len(config.SupportedLocales) > 0 {
+			locales := config.SupportedLocales
+			if !includeLocale(locales, config.GlobalLocale) {
+				locales = append([]string{config.GlobalLocale}, locales...)
+			}
+			return locales
+		}
+		return []string{config.GlobalLocale}
+	}
+	return middleware.SupportedLocales
+}
+
+func (middleware *Middleware) defaultLocale() string {
+	for _, locale := range middleware.supportedLocales() {
+		if GetConfig(middleware.DB).IsGlobal(locale) {
+			return locale
+		}
+	}
+	return middleware.supportedLocales()[0]
+}
+
+func (middleware *Middleware) queryParam() string {
+	if middleware.QueryParam == "" {
+		return "locale"
+	}
+	return middleware.QueryParam
+}
+
+func (middleware *Middleware) cookieName() string {
+	if middleware.CookieName == "" {
+		return "locale"
+	}
+	return middleware.CookieName
+}
+
+// LocaleFromContext get locale resolved by the middleware from context
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeContextKey).(string); ok {
+		return locale
+	}
+	return GetConfig(DBFromContext(ctx)).GlobalLocale
+}
+
+// DBFromContext get locale scoped DB set by the middleware from context
+func DBFromContext(ctx context.Context) *gorm.DB {
+	if db, ok := ctx.Value(dbContextKey).(*gorm.DB); ok {
+		return db
+	}
+	return nil
+}
+
+func normalizeLocale(locale string) string {
+	return strings.Replace(strings.TrimSpace(locale), "_", "-", -1)
+}
+
+// parseAcceptLanguage parse `Accept-Language` header, return languages ordered by quality
+func parseAcceptLanguage(header string) (languages []string) {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var results []language
+	for _, part := range strings.Split(header, ",") {
+		values := strings.Split(strings.TrimSpace(part), ";")
+		tag, quality := strings.TrimSpace(values[0]), 1.0
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		for _, value := range values[1:] {
+			if value = strings.TrimSpace(value); strings.HasPrefix(value, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(value, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if quality > 0 {
+			results = append(results, language{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].quality > results[j].quality })
+	for _, result := range results {
+		languages = append(languages, result.tag)
+	}
+	return
+}
@@ -0,0 +1,62 @@
+package l10n_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestMiddleware(t *testing.T) {
+	middleware := &l10n.Middleware{DB: dbGlobal, SupportedLocales: []string{l10n.Global, "zh-CN", "zh-TW", "de"}, StripURLPrefix: true}
+
+	var locale, path string
+	var dbLocale interface{}
+	handler := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		locale = l10n.LocaleFromContext(req.Context())
+		path = req.URL.Path
+		dbLocale, _ = l10n.DBFromContext(req.Context()).Get("l10n:locale")
+	}))
+
+	cases := []struct {
+		URL            string
+		Cookie         string
+		AcceptLanguage string
+		Locale         string
+		Path           string
+	}{
+		{URL: "/products", Locale: l10n.Global, Path: "/products"},
+		{URL: "/zh-CN/products", Locale: "zh-CN", Path: "/products"},
+		{URL: "/zh-cn", Locale: "zh-CN", Path: "/"},
+		{URL: "/products?locale=zh_TW", Locale: "zh-TW", Path: "/products"},
+		{URL: "/products", Cookie: "de", Locale: "de", Path: "/products"},
+		{URL: "/products", AcceptLanguage: "fr-FR, de-AT;q=0.8, zh-TW;q=0.9", Locale: "zh-TW", Path: "/products"},
+		{URL: "/products", AcceptLanguage: "fr-FR, zh;q=0.5", Locale: "zh-CN", Path: "/products"},
+		{URL: "/zh-HK/products", AcceptLanguage: "ja", Locale: l10n.Global, Path: "/zh-HK/products"},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", c.URL, nil)
+		if c.Cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "locale", Value: c.Cookie})
+		}
+		if c.AcceptLanguage != "" {
+			req.Header.Set("Accept-Language", c.AcceptLanguage)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		if vary := recorder.Header()["Vary"]; len(vary) != 2 || vary[0] != "Cookie" || vary[1] != "Accept-Language" {
+			t.Errorf("%v: response should vary by cookie and accept language, but got %v", c.URL, vary)
+		}
+
+		if locale != c.Locale || dbLocale != c.Locale {
+			t.Errorf("%v: locale should be %v, but got %v, db locale %v", c.URL, c.Locale, locale, dbLocale)
+		}
+
+		if path != c.Path {
+			t.Errorf("%v: path should be %v, but got %v", c.URL, c.Path, path)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package l10n
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Mode l10n query mode
+type Mode string
+
+const (
+	// ModeFallback find localized records, if not found, return the record from its fallback locales or the global one, it is the default mode
+	ModeFallback Mode = "fallback"
+	// ModeGlobal find all global records
+	ModeGlobal Mode = "global"
+	// ModeHidden find records that are hidden in the locale, requires `Hideable`
+	ModeHidden Mode = "hidden"
+	// ModeLocale find localized records
+	ModeLocale Mode = "locale"
+	// ModeReverse find global records that haven't been localized
+	ModeReverse Mode = "reverse"
+	// ModeStale find localized records that were translated from an outdated revision of the global record, requires `RevisionTracking`
+	ModeStale Mode = "stale"
+	// ModeUnscoped raw query, won't add locale conditions when querying
+	ModeUnscoped Mode = "unscoped"
+)
+
+var modes = []Mode{ModeFallback, ModeGlobal, ModeHidden, ModeLocale, ModeReverse, ModeStale, ModeUnscoped}
+
+// IsValid return if mode is a known query mode
+func (mode Mode) IsValid() bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// WithLocale return a new DB that query and save records in locale
+func WithLocale(db *gorm.DB, locale string) *gorm.DB {
+	return db.Set("l10n:locale", locale)
+}
+
+// WithMode return a new DB that query records with mode
+func WithMode(db *gorm.DB, mode Mode) *gorm.DB {
+	return db.Set("l10n:mode", mode)
+}
+
+func getMode(scope *gorm.Scope) (Mode, error) {
+	var mode Mode
+
+	switch value, _ := scope.DB().Get("l10n:mode"); value := value.(type) {
+	case nil:
+	case Mode:
+		mode = value
+	case string:
+		mode = Mode(value)
+	default:
+		return "", fmt.Errorf("l10n: invalid query mode %#v", value)
+	}
+
+	if mode == "" {
+		return ModeFallback, nil
+	}
+
+	if !mode.IsValid() {
+		return mode, fmt.Errorf("l10n: unknown query mode %q", mode)
+	}
+	return mode, nil
+}
@@ -0,0 +1,169 @@
+package l10n
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ExportPO export translatable fields of localizable models into gettext PO file, units' keys are used as msgctxt, global values as msgid, and values in locale as msgstr
+func ExportPO(db *gorm.DB, w io.Writer, locale string, models ...interface{}) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "msgid \"\"\nmsgstr \"\"\n%v\n%v\n%v\n",
+		quotePO("Content-Type: text/plain; charset=UTF-8\n"),
+		quotePO(fmt.Sprintf("Language: %v\n", locale)),
+		quotePO(fmt.Sprintf("X-Source-Language: %v\n", GetConfig(db).GlobalLocale)),
+	)
+
+	for _, model := range models {
+		units, err := ExportTranslationUnits(db, model, locale)
+		if err != nil {
+			return err
+		}
+
+		for _, unit := range units {
+			fmt.Fprintf(writer, "\nmsgctxt %v\nmsgid %v\nmsgstr %v\n", quotePO(unit.Key), quotePO(unit.Source), quotePO(unit.Target))
+		}
+	}
+	return writer.Flush()
+}
+
+// ImportPO import translations from gettext PO file into the language of its header, untranslated and fuzzy entries won't be imported, but reported in the result
+func ImportPO(db *gorm.DB, r io.Reader, models ...interface{}) (result ImportResult, err error) {
+	entries, err := parsePO(r)
+	if err != nil {
+		return result, err
+	}
+
+	var (
+		locale string
+		units  []TranslationUnit
+	)
+
+	for _, entry := range entries {
+		if entry.msgctxt == "" && entry.msgid == "" {
+			for _, line := range strings.Split(entry.msgstr, "\n") {
+				if values := strings.SplitN(line, ":", 2); len(values) == 2 && strings.TrimSpace(values[0]) == "Language" {
+					locale = strings.TrimSpace(values[1])
+				}
+			}
+			continue
+		}
+
+		if entry.msgctxt == "" {
+			result.Errors = append(result.Errors, ImportError{Key: entry.msgid, Err: fmt.Errorf("msgctxt is required")})
+			continue
+		}
+
+		if entry.fuzzy {
+			result.Fuzzy = append(result.Fuzzy, entry.msgctxt)
+			continue
+		}
+		units = append(units, TranslationUnit{Key: entry.msgctxt, Source: entry.msgid, Target: entry.msgstr})
+	}
+
+	if locale == "" {
+		return result, fmt.Errorf("language is required in PO header")
+	}
+
+	imported := ImportTranslationUnits(db, locale, units, models...)
+	result.Imported = imported.Imported
+	result.Untranslated = imported.Untranslated
+	result.Errors = append(result.Errors, imported.Errors...)
+	return result, nil
+}
+
+type poEntry struct {
+	msgctxt string
+	msgid   string
+	msgstr  string
+	fuzzy   bool
+}
+
+func parsePO(r io.Reader) (entries []poEntry, err error) {
+	var (
+		entry   poEntry
+		started bool
+		current *string
+		scanner = bufio.NewScanner(r)
+	)
+
+	flush := func() {
+		if started {
+			entries = append(entries, entry)
+		}
+		entry, started, current = poEntry{}, false, nil
+	}
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#,"):
+			if started {
+				flush()
+			}
+			for _, flag := range strings.Split(strings.TrimPrefix(line, "#,"), ",") {
+				if strings.TrimSpace(flag) == "fuzzy" {
+					entry.fuzzy = true
+				}
+			}
+		case strings.HasPrefix(line, "#"):
+			// comments and obsolete entries
+		case strings.HasPrefix(line, "\""):
+			if current == nil {
+				return nil, fmt.Errorf("line %v: unexpected string", lineNumber)
+			}
+			value, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+			}
+			*current += value
+		default:
+			values := strings.SplitN(line, " ", 2)
+			if len(values) != 2 {
+				return nil, fmt.Errorf("line %v: invalid syntax", lineNumber)
+			}
+
+			value, err := strconv.Unquote(strings.TrimSpace(values[1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", lineNumber, err)
+			}
+
+			switch values[0] {
+			case "msgctxt":
+				if started && entry.msgid != "" {
+					flush()
+				}
+				current = &entry.msgctxt
+			case "msgid":
+				if started && entry.msgid != "" {
+					flush()
+				}
+				current = &entry.msgid
+			case "msgstr", "msgstr[0]":
+				current = &entry.msgstr
+			default:
+				// ignore plural forms
+				var ignored string
+				current = &ignored
+			}
+			started = true
+			*current = value
+		}
+	}
+	flush()
+
+	return entries, scanner.Err()
+}
+
+func quotePO(str string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(str) + `"`
+}
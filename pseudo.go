@@ -0,0 +1,96 @@
+package l10n
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// PseudoLocale pseudo locale used to test UI, when querying with it, global records are returned with translatable fields pseudo-localized, it is read only
+var PseudoLocale = "qps-ploc"
+
+var pseudoCharacters = map[rune]rune{
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î', 'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ',
+	'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ', 'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î', 'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ṁ',
+	'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ', 's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+}
+
+// Pseudolocalize pseudo-localize text, letters are accented, text is expanded about 30% and bracketed, e.g: "Hello" => "[Ĥéļļö ··]", HTML tags and placeholders like `{name}` are kept
+func Pseudolocalize(text string) string {
+	if text == "" {
+		return text
+	}
+
+	var (
+		result  []rune
+		letters int
+		closing rune
+	)
+
+	for _, r := range text {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			}
+		case r == '<':
+			closing = '>'
+		case r == '{':
+			closing = '}'
+		default:
+			if pseudo, ok := pseudoCharacters[r]; ok {
+				r = pseudo
+			}
+			letters++
+		}
+		result = append(result, r)
+	}
+
+	padding := (letters*3 + 9) / 10
+	if padding > 0 {
+		return "[" + string(result) + " " + strings.Repeat("·", padding) + "]"
+	}
+	return "[" + string(result) + "]"
+}
+
+func isPseudoLocalized(scope *gorm.Scope) bool {
+	locale, _ := getQueryLocale(scope)
+	return locale == PseudoLocale
+}
+
+func isPseudoLocale(scope *gorm.Scope) bool {
+	locale, _ := getLocale(scope)
+	return locale == PseudoLocale
+}
+
+// pseudolocalizeFields pseudo-localize translatable fields of queried records, sync fields are kept
+func pseudolocalizeFields(scope *gorm.Scope) {
+	var (
+		fields  = exchangeFields(scope)
+		records = scope.IndirectValue()
+		values  []reflect.Value
+	)
+
+	if records.Kind() == reflect.Slice {
+		for i := 0; i < records.Len(); i++ {
+			values = append(values, reflect.Indirect(records.Index(i)))
+		}
+	} else {
+		values = append(values, records)
+	}
+
+	for _, value := range values {
+		if !value.CanAddr() {
+			continue
+		}
+
+		recordScope := scope.New(value.Addr().Interface())
+		for _, structField := range fields {
+			if field, ok := recordScope.FieldByName(structField.Name); ok {
+				field.Field.SetString(Pseudolocalize(field.Field.String()))
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestPseudolocalize(t *testing.T) {
+	if result := l10n.Pseudolocalize("Hello {name}, <b>welcome</b>"); result != "[Ĥéļļö {name}, <b>ŵéļçöṁé</b> ·····]" {
+		t.Errorf("should pseudo-localize text, but got %v", result)
+	}
+}
+
+func TestPseudoLocale(t *testing.T) {
+	product := Product{Code: "PseudoLocale", Name: "Shirt"}
+	dbGlobal.Create(&product)
+	dbCN.Create(&product)
+
+	dbPseudo := dbGlobal.Set("l10n:locale", l10n.PseudoLocale)
+
+	var products []Product
+	dbPseudo.Where("code = ?", "PseudoLocale").Find(&products)
+	if len(products) != 1 || products[0].Name != "[Šĥîŕţ ··]" || products[0].Code != "PseudoLocale" || products[0].LanguageCode != l10n.Global {
+		t.Errorf("should return pseudo-localized global records, but got %#v", products)
+	}
+
+	if err := dbPseudo.Save(&product).Error; err == nil {
+		t.Errorf("should not save records into pseudo locale")
+	}
+
+	if value := (l10n.LocalizedString{l10n.Global: "Size"}).Get(l10n.PseudoLocale); value != "[Šîžé ··]" {
+		t.Errorf("should pseudo-localize localized strings, but got %v", value)
+	}
+}
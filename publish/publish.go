@@ -2,7 +2,6 @@ package publish
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/jinzhu/gorm"
 	"github.com/qor/admin"
@@ -23,19 +22,19 @@ type editableLocalesInterface interface {
 	EditableLocales() []string
 }
 
-func getPublishableLocales(req *http.Request, currentUser interface{}) []string {
-	if user, ok := currentUser.(publishableLocalesInterface); ok {
+func getPublishableLocales(context *qor.Context) []string {
+	if user, ok := context.CurrentUser.(publishableLocalesInterface); ok {
 		return user.PublishableLocales()
 	}
 
-	if user, ok := currentUser.(editableLocalesInterface); ok {
+	if user, ok := context.CurrentUser.(editableLocalesInterface); ok {
 		return user.EditableLocales()
 	}
 
-	if user, ok := currentUser.(availableLocalesInterface); ok {
+	if user, ok := context.CurrentUser.(availableLocalesInterface); ok {
 		return user.AvailableLocales()
 	}
-	return []string{l10n.Global}
+	return []string{l10n.GetConfig(context.GetDB()).GlobalLocale}
 }
 
 // RegisterL10nForPublish register l10n language switcher for publish
@@ -44,23 +43,27 @@ func RegisterL10nForPublish(Publish *publish.Publish, Admin *admin.Admin) {
 	Publish.SearchHandler = func(db *gorm.DB, context *qor.Context) *gorm.DB {
 		if context != nil {
 			if context.Request != nil && context.Request.URL.Query().Get("locale") == "" {
-				publishableLocales := getPublishableLocales(context.Request, context.CurrentUser)
-				return searchHandler(db, context).Set("l10n:mode", "unscoped").Scopes(func(db *gorm.DB) *gorm.DB {
+				publishableLocales := getPublishableLocales(context)
+				return searchHandler(db, context).Set("l10n:mode", l10n.ModeUnscoped).Scopes(func(db *gorm.DB) *gorm.DB {
 					scope := db.NewScope(db.Value)
 					if l10n.IsLocalizable(scope) {
-						return db.Where(fmt.Sprintf("%v.language_code IN (?)", scope.QuotedTableName()), publishableLocales)
+						var locales []interface{}
+						for _, locale := range publishableLocales {
+							locales = append(locales, l10n.LocaleValue(scope, locale))
+						}
+						return db.Where(fmt.Sprintf("%v.%v IN (?)", scope.QuotedTableName(), scope.Quote(l10n.LanguageColumn(scope))), locales)
 					}
 					return db
 				})
 			}
-			return searchHandler(db, context).Set("l10n:mode", "locale")
+			return searchHandler(db, context).Set("l10n:mode", l10n.ModeLocale)
 		}
-		return searchHandler(db, context).Set("l10n:mode", "unscoped")
+		return searchHandler(db, context).Set("l10n:mode", l10n.ModeUnscoped)
 	}
 
 	Admin.RegisterViewPath("github.com/qor/l10n/publish/views")
 
 	Admin.RegisterFuncMap("publishable_locales", func(context admin.Context) []string {
-		return getPublishableLocales(context.Request, context.CurrentUser)
+		return getPublishableLocales(context.Context)
 	})
 }
@@ -0,0 +1,106 @@
+package l10n
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/jinzhu/gorm"
+)
+
+// RevisionTracking embed this struct with `l10n.Locale` into models to track which revision of the global record localized records were translated from, e.g:
+//
+//	type Product struct {
+//	  gorm.Model
+//	  Name string
+//	  l10n.Locale
+//	  l10n.RevisionTracking
+//	}
+//
+// The global record's revision is a hash of its translatable (non-sync) fields, when saving localized records, they will be marked as translated from the global record's current revision, so localized records that need re-translation could be found with the `stale` mode or `StaleRecords`
+type RevisionTracking struct {
+	L10nRevision string `sql:"size:40"`
+}
+
+func (RevisionTracking) trackRevision() {}
+
+type revisionTrackingInterface interface {
+	trackRevision()
+}
+
+func isRevisionTracked(scope *gorm.Scope) (ok bool) {
+	if scope.GetModelStruct().ModelType == nil {
+		return false
+	}
+	_, ok = reflect.New(scope.GetModelStruct().ModelType).Interface().(revisionTrackingInterface)
+	return
+}
+
+// StaleRecords find localized records in locale that were translated from an outdated revision of the global record, records should be a pointer of slice, e.g:
+//
+//	var products []Product
+//	l10n.StaleRecords(db, &products, "zh-CN")
+func StaleRecords(db *gorm.DB, records interface{}, locale string) error {
+	return WithMode(WithLocale(db, locale), ModeStale).Find(records).Error
+}
+
+// revisionOf calculate revision of the record from its translatable fields
+func revisionOf(scope *gorm.Scope) string {
+	hash := sha1.New()
+	for _, structField := range translatableFields(scope) {
+		if field, ok := scope.FieldByName(structField.Name); ok {
+			value, _ := json.Marshal(field.Field.Interface())
+			fmt.Fprintf(hash, "%v=%s\n", field.DBName, value)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// setRevision set record's revision, including the attributes that will be updated
+func setRevision(scope *gorm.Scope, revision string) {
+	if field, ok := scope.FieldByName("L10nRevision"); ok {
+		field.Set(revision)
+
+		if updateAttrs, ok := scope.InstanceGet("gorm:update_attrs"); ok {
+			updateAttrs.(map[string]interface{})[field.DBName] = revision
+		}
+	}
+}
+
+// globalRevision get current revision of the record's global record
+func globalRevision(scope *gorm.Scope) (revision string) {
+	condition, primaryKeys, err := primaryKeyCondition(scope)
+	if err != nil {
+		return
+	}
+
+	if row := scope.NewDB().Table(scope.TableName()).Select("l10n_revision").Where(condition, primaryKeys...).Where(fmt.Sprintf("%v = ?", scope.Quote(LanguageColumn(scope))), LocaleValue(scope, getConfig(scope).GlobalLocale)).Row(); row != nil {
+		var value *string
+		if row.Scan(&value) == nil && value != nil {
+			revision = *value
+		}
+	}
+	return
+}
+
+// refreshGlobalRevision recalculate the global record's revision after it is updated
+func refreshGlobalRevision(scope *gorm.Scope) {
+	condition, primaryKeys, err := primaryKeyCondition(scope)
+	if err != nil {
+		return
+	}
+	globalCondition := fmt.Sprintf("%v.%v = ?", scope.QuotedTableName(), scope.Quote(LanguageColumn(scope)))
+
+	record := reflect.New(scope.GetModelStruct().ModelType).Interface()
+	if err := scope.NewDB().Set("l10n:mode", ModeUnscoped).Where(condition, primaryKeys...).Where(globalCondition, LocaleValue(scope, getConfig(scope).GlobalLocale)).First(record).Error; err != nil {
+		return
+	}
+
+	recordScope := scope.New(record)
+	if revision := revisionOf(recordScope); revision != globalRevision(scope) {
+		scope.Err(scope.NewDB().Table(scope.TableName()).Where(condition, primaryKeys...).Where(globalCondition, LocaleValue(scope, getConfig(scope).GlobalLocale)).UpdateColumn("l10n_revision", revision).Error)
+		setRevision(scope, revision)
+	}
+}
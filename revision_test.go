@@ -0,0 +1,61 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func checkStalePosts(t *testing.T, locale string, expected int) {
+	var posts []Post
+	checkHasErr(t, l10n.StaleRecords(dbGlobal.Where("code = ?", "Revision"), &posts, locale))
+	if len(posts) != expected {
+		t.Errorf("should find %v stale posts in %v, but found %v", expected, locale, len(posts))
+	}
+
+	for _, post := range posts {
+		if post.LanguageCode != locale {
+			t.Errorf("stale post should be in locale %v, but got %v", locale, post.LanguageCode)
+		}
+	}
+}
+
+func TestRevisionTracking(t *testing.T) {
+	post := Post{Code: "Revision", Title: "global title", Body: "global body"}
+	checkHasErr(t, dbGlobal.Create(&post).Error)
+	if post.L10nRevision == "" {
+		t.Errorf("should set revision for global post")
+	}
+
+	post.Title = "中文标题"
+	checkHasErr(t, dbCN.Create(&post).Error)
+	post.Title = "English Title"
+	checkHasErr(t, dbEN.Create(&post).Error)
+
+	checkStalePosts(t, "zh", 0)
+	checkStalePosts(t, "en", 0)
+
+	// update sync fields won't make translations stale
+	var globalPost Post
+	dbGlobal.First(&globalPost, post.ID)
+	globalPost.Code = "Revision"
+	checkHasErr(t, dbGlobal.Save(&globalPost).Error)
+	checkStalePosts(t, "zh", 0)
+
+	// update translatable fields
+	checkHasErr(t, dbGlobal.Model(&globalPost).Updates(map[string]interface{}{"body": "new global body"}).Error)
+	checkStalePosts(t, "zh", 1)
+	checkStalePosts(t, "en", 1)
+
+	// re-translate
+	var postCN Post
+	dbCN.First(&postCN, post.ID)
+	postCN.Body = "新的中文内容"
+	checkHasErr(t, dbCN.Save(&postCN).Error)
+	checkStalePosts(t, "zh", 0)
+	checkStalePosts(t, "en", 1)
+
+	if err := l10n.WithMode(dbCN, l10n.ModeStale).Find(&[]Product{}).Error; err == nil {
+		t.Errorf("should return error for models that don't track revisions")
+	}
+}
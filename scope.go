@@ -1,7 +1,9 @@
 package l10n
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/jinzhu/gorm"
 	"github.com/qor/qor/utils"
@@ -40,19 +42,178 @@ func setLocale(scope *gorm.Scope, locale string) {
 	}
 }
 
+// languageField return the `LanguageCode` field of localizable models
+func languageField(scope *gorm.Scope) *gorm.StructField {
+	if scope.GetModelStruct().ModelType != nil {
+		for _, field := range scope.GetModelStruct().StructFields {
+			if field.Name == "LanguageCode" {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// LanguageColumn return column name of locales, which is the column of localizable models' `LanguageCode` field, change it with gorm's `column` tag, or the configured column for translation tables
+func LanguageColumn(scope *gorm.Scope) string {
+	if field := languageField(scope); field != nil {
+		return field.DBName
+	}
+	return getConfig(scope).LanguageColumn
+}
+
+// languageType return type of the `LanguageCode` field, nil for translation tables
+func languageType(scope *gorm.Scope) reflect.Type {
+	if field := languageField(scope); field != nil {
+		return field.Struct.Type
+	}
+	return nil
+}
+
+// convertLocale convert locale to the language field's type, so custom types could save locales as other values, e.g: IDs of a locales table
+func convertLocale(typ reflect.Type, locale string) interface{} {
+	if typ == nil || typ == reflect.TypeOf(locale) || !reflect.TypeOf(locale).ConvertibleTo(typ) {
+		return locale
+	}
+	return reflect.ValueOf(locale).Convert(typ).Interface()
+}
+
+// LocaleValue return value of locale used in SQL conditions of the model's language column, locales are converted to the type of the `LanguageCode` field if it is a custom type, e.g: a string type implements `driver.Valuer` and `sql.Scanner` to save IDs of a locales table into `locale_id`
+func LocaleValue(scope *gorm.Scope, locale string) interface{} {
+	return convertLocale(languageType(scope), locale)
+}
+
+// localeValues return values of locales used in `IN (?)` conditions of the model's language column
+func localeValues(scope *gorm.Scope, locales []string) []interface{} {
+	values := []interface{}{}
+	for _, locale := range locales {
+		values = append(values, LocaleValue(scope, locale))
+	}
+	return values
+}
+
+// pluckLocales pluck distinct locales of column, values of custom types are converted back to locales
+func pluckLocales(db *gorm.DB, typ reflect.Type, column string) ([]string, error) {
+	if typ == nil {
+		typ = reflect.TypeOf("")
+	}
+
+	values := reflect.New(reflect.SliceOf(typ))
+	if err := db.Pluck(fmt.Sprintf("DISTINCT %v", column), values.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	var locales []string
+	for i := 0; i < values.Elem().Len(); i++ {
+		locales = append(locales, values.Elem().Index(i).String())
+	}
+	return locales, nil
+}
+
+// primaryFields return primary fields of the record except the `LanguageCode` field
+func primaryFields(scope *gorm.Scope) (fields []*gorm.Field) {
+	for _, field := range scope.PrimaryFields() {
+		if field.Name != "LanguageCode" {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// quotedPrimaryKeys return quoted columns of primary keys except the language column
+func quotedPrimaryKeys(scope *gorm.Scope) (columns []string) {
+	for _, field := range scope.GetModelStruct().PrimaryFields {
+		if field.Name != "LanguageCode" {
+			columns = append(columns, scope.Quote(field.DBName))
+		}
+	}
+	return
+}
+
+// isBlankPrimaryKey return the primary key is blank or not, zero is a valid value of numeric keys that don't auto increment, e.g: `shop_id = 0` of keys tagged with `auto_increment:false`
+func isBlankPrimaryKey(field *gorm.Field) bool {
+	if !field.IsBlank {
+		return false
+	}
+
+	if value, ok := field.TagSettings["AUTO_INCREMENT"]; ok && strings.ToLower(value) == "false" {
+		switch field.Field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return false
+		}
+	}
+	return true
+}
+
+// primaryKeyValues return values of the record's primary keys except the language code, return false if any of them is blank
+func primaryKeyValues(scope *gorm.Scope) (values []interface{}, ok bool) {
+	for _, field := range primaryFields(scope) {
+		if isBlankPrimaryKey(field) {
+			return nil, false
+		}
+		values = append(values, field.Field.Interface())
+	}
+	return values, len(values) > 0
+}
+
+// primaryKeysCondition return condition to find records with primary keys, each of primary keys is values of a record's primary key columns, e.g: products.id IN (?), or (skus.shop_id = ? AND skus.code = ?) OR (...) for composite primary keys
+func primaryKeysCondition(quotedTableName string, quotedPrimaryKeys []string, primaryKeys [][]interface{}) (string, []interface{}) {
+	var prefix string
+	if quotedTableName != "" {
+		prefix = quotedTableName + "."
+	}
+
+	if len(quotedPrimaryKeys) == 1 {
+		var values []interface{}
+		for _, primaryKey := range primaryKeys {
+			values = append(values, primaryKey[0])
+		}
+		return fmt.Sprintf("%v%v IN (?)", prefix, quotedPrimaryKeys[0]), []interface{}{values}
+	}
+
+	var (
+		conditions []string
+		values     []interface{}
+		columns    []string
+	)
+
+	for _, quotedPrimaryKey := range quotedPrimaryKeys {
+		columns = append(columns, fmt.Sprintf("%v%v = ?", prefix, quotedPrimaryKey))
+	}
+
+	for _, primaryKey := range primaryKeys {
+		conditions = append(conditions, "("+strings.Join(columns, " AND ")+")")
+		values = append(values, primaryKey...)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", values
+}
+
+// primaryKeyString format values of a record's primary keys as a string, used as keys of maps
+func primaryKeyString(values []interface{}) string {
+	var strs []string
+	for _, value := range values {
+		strs = append(strs, fmt.Sprint(value))
+	}
+	return strings.Join(strs, ",")
+}
+
 func getQueryLocale(scope *gorm.Scope) (locale string, isLocale bool) {
+	config := getConfig(scope)
 	if str, ok := scope.DB().Get("l10n:locale"); ok {
 		if locale, ok := str.(string); ok && locale != "" {
-			return locale, locale != Global
+			locale = registeredLocale(config, locale)
+			return locale, locale != config.GlobalLocale
 		}
 	}
-	return Global, false
+	return config.GlobalLocale, false
 }
 
 func getLocale(scope *gorm.Scope) (locale string, isLocale bool) {
 	if str, ok := scope.DB().Get("l10n:localize_to"); ok {
 		if locale, ok := str.(string); ok && locale != "" {
-			return locale, locale != Global
+			config := getConfig(scope)
+			locale = registeredLocale(config, locale)
+			return locale, locale != config.GlobalLocale
 		}
 	}
 
@@ -74,3 +235,19 @@ func syncColumns(scope *gorm.Scope) (columns []string) {
 	}
 	return
 }
+
+// translatableFields return fields that could be translated, which are normal fields except primary keys, sync fields and timestamps
+func translatableFields(scope *gorm.Scope) (fields []*gorm.StructField) {
+	for _, field := range scope.GetModelStruct().StructFields {
+		if !field.IsNormal || field.IsIgnored || field.IsPrimaryKey || isSyncField(field) {
+			continue
+		}
+
+		switch field.Name {
+		case "LanguageCode", "L10nRevision", "L10nHidden", "L10nPlaceholder", "CreatedAt", "UpdatedAt", "DeletedAt":
+		default:
+			fields = append(fields, field)
+		}
+	}
+	return
+}
@@ -0,0 +1,333 @@
+package l10n
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SpreadsheetImportOptions options for importing translation spreadsheets
+type SpreadsheetImportOptions struct {
+	// DryRun only report changes, won't save them
+	DryRun bool
+	// EditableLocales locales that are allowed to import, no limitation if blank
+	EditableLocales []string
+}
+
+// SpreadsheetReport report of importing a translation spreadsheet
+type SpreadsheetReport struct {
+	Locale  string
+	Changes []SpreadsheetChange
+	Errors  []SpreadsheetError
+}
+
+// SpreadsheetChange a changed translation in the spreadsheet
+type SpreadsheetChange struct {
+	Row    int
+	Key    string
+	Column string
+	Old    string
+	New    string
+}
+
+// SpreadsheetError error of a row in the spreadsheet
+type SpreadsheetError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (err SpreadsheetError) Error() string {
+	if err.Column != "" {
+		return fmt.Sprintf("row %v, %v: %v", err.Row, err.Column, err.Err)
+	}
+	return fmt.Sprintf("row %v: %v", err.Row, err.Err)
+}
+
+var spreadsheetHeaderRegexp = regexp.MustCompile(`^(.+) \((.+)\)$`)
+
+// spreadsheetRows build rows of the spreadsheet, one row for each global record, with columns of primary key, global value of sync fields, and global and localized values of translatable fields
+func spreadsheetRows(db *gorm.DB, model interface{}, locale string) (rows [][]string, err error) {
+	scope := db.NewScope(model)
+	if !IsLocalizable(scope) {
+		return nil, fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+	}
+
+	globalLocale := GetConfig(db).GlobalLocale
+	if locale == globalLocale {
+		return nil, fmt.Errorf("target locale can't be the global locale")
+	}
+
+	var (
+		syncFields     []*gorm.StructField
+		fields         = exchangeFields(scope)
+		header         = []string{primaryKeyHeader(scope)}
+		modelType      = scope.GetModelStruct().ModelType
+		globalRecords  = reflect.New(reflect.SliceOf(modelType))
+		localeRecords  = reflect.New(reflect.SliceOf(modelType))
+		localizedIndex = map[string]*gorm.Scope{}
+	)
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal && !field.IsPrimaryKey && isSyncField(field) && field.Struct.Type.Kind() == reflect.String {
+			syncFields = append(syncFields, field)
+			header = append(header, fmt.Sprintf("%v (%v)", field.DBName, globalLocale))
+		}
+	}
+
+	for _, field := range fields {
+		header = append(header, fmt.Sprintf("%v (%v)", field.DBName, globalLocale), fmt.Sprintf("%v (%v)", field.DBName, locale))
+	}
+	rows = append(rows, header)
+
+	var orderBy []string
+	for _, quotedPrimaryKey := range quotedPrimaryKeys(scope) {
+		orderBy = append(orderBy, fmt.Sprintf("%v.%v", scope.QuotedTableName(), quotedPrimaryKey))
+	}
+
+	if err = WithMode(db, ModeGlobal).Order(strings.Join(orderBy, ", ")).Find(globalRecords.Interface()).Error; err != nil {
+		return
+	}
+
+	if err = WithMode(WithLocale(db, locale), ModeLocale).Find(localeRecords.Interface()).Error; err != nil {
+		return
+	}
+
+	for i := 0; i < localeRecords.Elem().Len(); i++ {
+		recordScope := db.NewScope(localeRecords.Elem().Index(i).Addr().Interface())
+		localizedIndex[recordPrimaryKey(recordScope)] = recordScope
+	}
+
+	for i := 0; i < globalRecords.Elem().Len(); i++ {
+		recordScope := db.NewScope(globalRecords.Elem().Index(i).Addr().Interface())
+		primaryKey := recordPrimaryKey(recordScope)
+		row := []string{primaryKey}
+
+		for _, field := range syncFields {
+			value, _ := recordScope.FieldByName(field.Name)
+			row = append(row, value.Field.String())
+		}
+
+		localizedScope := localizedIndex[primaryKey]
+		for _, field := range fields {
+			value, _ := recordScope.FieldByName(field.Name)
+			row = append(row, value.Field.String(), "")
+
+			if localizedScope != nil {
+				if localizedValue, ok := localizedScope.FieldByName(field.Name); ok {
+					row[len(row)-1] = localizedValue.Field.String()
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+// importSpreadsheetRows validate rows of the spreadsheet, and import changed translations
+func importSpreadsheetRows(db *gorm.DB, model interface{}, rows [][]string, options SpreadsheetImportOptions) (report SpreadsheetReport, err error) {
+	scope := db.NewScope(model)
+	if !IsLocalizable(scope) {
+		return report, fmt.Errorf("%v is not localizable", scope.GetModelStruct().ModelType.Name())
+	}
+
+	if len(rows) == 0 {
+		return report, fmt.Errorf("header is required")
+	}
+
+	type column struct {
+		field  *gorm.StructField
+		locale string
+	}
+
+	var (
+		primaryHeader = primaryKeyHeader(scope)
+		columns       = make([]*column, len(rows[0]))
+		primaryColumn = -1
+		translatable  = map[string]bool{}
+		globalLocale  = GetConfig(db).GlobalLocale
+	)
+
+	for _, field := range exchangeFields(scope) {
+		translatable[field.DBName] = true
+	}
+
+	// validate header
+	for idx, name := range rows[0] {
+		name = strings.TrimSpace(name)
+		if name == primaryHeader {
+			primaryColumn = idx
+			continue
+		}
+
+		matches := spreadsheetHeaderRegexp.FindStringSubmatch(name)
+		if matches == nil {
+			return report, fmt.Errorf("unknown column %v", name)
+		}
+
+		field, ok := scope.FieldByName(matches[1])
+		if !ok || !field.IsNormal {
+			return report, fmt.Errorf("unknown column %v", name)
+		}
+
+		if locale := matches[2]; locale != globalLocale {
+			if report.Locale != "" && report.Locale != locale {
+				return report, fmt.Errorf("only one target locale is allowed, but got %v and %v", report.Locale, locale)
+			}
+
+			if isSyncField(field.StructField) {
+				return report, fmt.Errorf("sync column %v can't be localized", field.DBName)
+			}
+
+			if !translatable[field.DBName] {
+				return report, fmt.Errorf("column %v is not translatable", field.DBName)
+			}
+			report.Locale = locale
+		}
+		columns[idx] = &column{field: field.StructField, locale: matches[2]}
+	}
+
+	if primaryColumn == -1 {
+		return report, fmt.Errorf("primary key column %v is required", primaryHeader)
+	}
+
+	if report.Locale == "" {
+		return report, fmt.Errorf("target locale column is required")
+	}
+
+	if len(options.EditableLocales) > 0 && !includeLocale(options.EditableLocales, report.Locale) {
+		return report, fmt.Errorf("locale %v is not editable", report.Locale)
+	}
+
+	var (
+		units    []TranslationUnit
+		unitRows = map[string]int{}
+	)
+
+	for rowIdx, row := range rows[1:] {
+		var (
+			rowNumber  = rowIdx + 2
+			primaryKey string
+			global     = reflect.New(scope.GetModelStruct().ModelType).Interface()
+			localized  = reflect.New(scope.GetModelStruct().ModelType).Interface()
+		)
+
+		// skip empty rows
+		if len(row) == 0 {
+			continue
+		}
+
+		if primaryColumn < len(row) {
+			primaryKey = strings.TrimSpace(row[primaryColumn])
+		}
+
+		if primaryKey == "" {
+			report.Errors = append(report.Errors, SpreadsheetError{Row: rowNumber, Err: fmt.Errorf("primary key is blank")})
+			continue
+		}
+
+		condition, primaryValues, err := ParsePrimaryKeys(scope, primaryKey)
+		if err != nil {
+			report.Errors = append(report.Errors, SpreadsheetError{Row: rowNumber, Err: err})
+			continue
+		}
+
+		if WithMode(db, ModeGlobal).Where(condition, primaryValues...).First(global).RecordNotFound() {
+			report.Errors = append(report.Errors, SpreadsheetError{Row: rowNumber, Err: fmt.Errorf("unknown %v %v", primaryHeader, primaryKey)})
+			continue
+		}
+
+		var (
+			globalScope    = db.NewScope(global)
+			localizedScope *gorm.Scope
+			rowErrors      []SpreadsheetError
+			rowChanges     []SpreadsheetChange
+		)
+
+		if !WithMode(WithLocale(db, report.Locale), ModeLocale).Where(condition, primaryValues...).First(localized).RecordNotFound() {
+			localizedScope = db.NewScope(localized)
+		}
+
+		for idx, column := range columns {
+			if column == nil || idx >= len(row) {
+				continue
+			}
+
+			if column.locale == globalLocale {
+				if value, _ := globalScope.FieldByName(column.field.Name); value.Field.String() != row[idx] {
+					err := fmt.Errorf("global value can't be edited")
+					if isSyncField(column.field) {
+						err = fmt.Errorf("sync column can't be edited")
+					}
+					rowErrors = append(rowErrors, SpreadsheetError{Row: rowNumber, Column: rows[0][idx], Err: err})
+				}
+				continue
+			}
+
+			var old string
+			if localizedScope != nil {
+				value, _ := localizedScope.FieldByName(column.field.Name)
+				old = value.Field.String()
+			}
+
+			if row[idx] != "" && row[idx] != old {
+				rowChanges = append(rowChanges, SpreadsheetChange{
+					Row:    rowNumber,
+					Key:    translationUnitKey(scope.TableName(), primaryKey, column.field.DBName),
+					Column: column.field.DBName,
+					Old:    old,
+					New:    row[idx],
+				})
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+
+		for _, change := range rowChanges {
+			units = append(units, TranslationUnit{Key: change.Key, Target: change.New})
+			unitRows[change.Key] = change.Row
+		}
+		report.Changes = append(report.Changes, rowChanges...)
+	}
+
+	if !options.DryRun && len(units) > 0 {
+		result := ImportTranslationUnits(db, report.Locale, units, model)
+		for _, importErr := range result.Errors {
+			report.Errors = append(report.Errors, SpreadsheetError{Row: unitRows[importErr.Key], Err: importErr})
+		}
+	}
+	return report, nil
+}
+
+// ExportCSV export a translation spreadsheet of model as CSV, one row for each record, with columns for global and localized values of each translatable field
+func ExportCSV(db *gorm.DB, w io.Writer, model interface{}, locale string) error {
+	rows, err := spreadsheetRows(db, model, locale)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// ImportCSV import translations from a CSV spreadsheet exported by ExportCSV, and report the changes and errors
+func ImportCSV(db *gorm.DB, r io.Reader, model interface{}, options SpreadsheetImportOptions) (SpreadsheetReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return SpreadsheetReport{}, err
+	}
+	return importSpreadsheetRows(db, model, rows, options)
+}
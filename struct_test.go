@@ -1,6 +1,8 @@
 package l10n_test
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -13,6 +15,7 @@ type Product struct {
 	Code            string `l10n:"sync"`
 	Quantity        uint   `l10n:"sync"`
 	Name            string
+	Description     string `l10n:"fallback"`
 	DeletedAt       *time.Time
 	ColorVariations []ColorVariation
 	BrandID         uint `l10n:"sync"`
@@ -55,6 +58,95 @@ type Category struct {
 	l10n.Locale
 }
 
+type Article struct {
+	ID    int `gorm:"primary_key"`
+	Code  string
+	Title string `l10n:"translate"`
+	Body  string `l10n:"translate"`
+	l10n.Translatable
+}
+
+type Option struct {
+	ID    int                  `gorm:"primary_key"`
+	Label l10n.LocalizedString `sql:"type:text"`
+}
+
+type Post struct {
+	ID    int    `gorm:"primary_key"`
+	Code  string `l10n:"sync"`
+	Title string
+	Body  string
+	l10n.Locale
+	l10n.RevisionTracking
+}
+
+type Banner struct {
+	ID    int `gorm:"primary_key"`
+	Title string
+	l10n.Locale
+	l10n.Hideable
+}
+
+type Page struct {
+	ID           int `gorm:"primary_key"`
+	Title        string
+	LanguageCode string `sql:"size:20" gorm:"primary_key;column:locale"`
+}
+
+func (page Page) IsGlobal() bool {
+	return page.LanguageCode == l10n.Global
+}
+
+func (page *Page) SetLocale(locale string) {
+	page.LanguageCode = locale
+}
+
+type SKU struct {
+	ShopID      int    `gorm:"primary_key;auto_increment:false"`
+	Code        string `gorm:"primary_key"`
+	Name        string
+	Description string `l10n:"fallback"`
+	Stock       int    `l10n:"sync"`
+	l10n.Locale
+}
+
+// LocaleID locale saved as ID of a locales table
+type LocaleID string
+
+var localeIDs = []string{l10n.Global, "zh", "en"}
+
+func (id LocaleID) Value() (driver.Value, error) {
+	for idx, locale := range localeIDs {
+		if locale == string(id) {
+			return int64(idx + 1), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown locale %v", string(id))
+}
+
+func (id *LocaleID) Scan(value interface{}) error {
+	if value, ok := value.(int64); ok && value > 0 && int(value) <= len(localeIDs) {
+		*id = LocaleID(localeIDs[value-1])
+		return nil
+	}
+	return fmt.Errorf("unknown locale id %v", value)
+}
+
+type Menu struct {
+	ID           int `gorm:"primary_key"`
+	Title        string
+	LanguageCode LocaleID `sql:"type:integer" gorm:"primary_key;column:locale_id"`
+	l10n.LocaleResource
+}
+
+func (menu Menu) IsGlobal() bool {
+	return string(menu.LanguageCode) == l10n.Global
+}
+
+func (menu *Menu) SetLocale(locale string) {
+	menu.LanguageCode = LocaleID(locale)
+}
+
 var dbGlobal, dbCN, dbEN *gorm.DB
 
 func init() {
@@ -67,7 +159,16 @@ func init() {
 	db.DropTableIfExists(&Category{})
 	db.Exec("drop table product_tags;")
 	db.Exec("drop table product_categories;")
-	db.AutoMigrate(&Product{}, &Brand{}, &Tag{}, &Category{})
+	db.DropTableIfExists(&Article{})
+	db.DropTableIfExists(&Option{})
+	db.DropTableIfExists(&Post{})
+	db.DropTableIfExists(&Banner{})
+	db.DropTableIfExists(&Page{})
+	db.DropTableIfExists(&SKU{})
+	db.DropTableIfExists(&Menu{})
+	db.DropTableIfExists("articles_translations")
+	db.AutoMigrate(&Product{}, &Brand{}, &Tag{}, &Category{}, &Article{}, &Option{}, &Post{}, &Banner{}, &Page{}, &SKU{}, &Menu{})
+	l10n.AutoMigrateTranslations(db, &Article{})
 
 	dbGlobal = db
 	dbCN = dbGlobal.Set("l10n:locale", "zh")
@@ -0,0 +1,375 @@
+package l10n
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/qor/qor/utils"
+)
+
+// Translatable embed this struct into GORM-backend models to save fields tagged with `l10n:"translate"` into a separate translation table `<table>_translations`, keyed by the record's primary keys and the language column (`language_code` by default, see `Config`), instead of duplicating whole records for each locale, e.g:
+//
+//	type Product struct {
+//	  gorm.Model
+//	  Code        string
+//	  Name        string `l10n:"translate"`
+//	  Description string `l10n:"translate"`
+//	  l10n.Translatable
+//	}
+//
+// The main table keeps global values, fields that are not translatable are shared by all locales
+type Translatable struct {
+	// TranslationLocale locale of the translation used by the record when querying, blank if it is global
+	TranslationLocale string `sql:"-"`
+}
+
+func (Translatable) translatable() {}
+
+type translatableInterface interface {
+	translatable()
+}
+
+type translationTableNameInterface interface {
+	TranslationTableName() string
+}
+
+// IsTranslatable return model saves its translations into a separate translation table or not
+func IsTranslatable(scope *gorm.Scope) (isTranslatable bool) {
+	if scope.GetModelStruct().ModelType == nil {
+		return false
+	}
+	_, isTranslatable = reflect.New(scope.GetModelStruct().ModelType).Interface().(translatableInterface)
+	return
+}
+
+// TranslationTableName return translation table's name of the model, default is `<table>_translations`, could be customized by defining method `TranslationTableName() string` for the model
+func TranslationTableName(scope *gorm.Scope) string {
+	if value, ok := reflect.New(scope.GetModelStruct().ModelType).Interface().(translationTableNameInterface); ok {
+		return value.TranslationTableName()
+	}
+	return scope.TableName() + "_translations"
+}
+
+func isTranslateField(field *gorm.StructField) bool {
+	if _, ok := utils.ParseTagOption(field.Tag.Get("l10n"))["TRANSLATE"]; ok {
+		return true
+	}
+	return false
+}
+
+func translateFields(scope *gorm.Scope) (fields []*gorm.StructField) {
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal && !field.IsIgnored && !field.IsPrimaryKey && isTranslateField(field) {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// AutoMigrateTranslations create or migrate translation tables for translatable models
+func AutoMigrateTranslations(db *gorm.DB, values ...interface{}) error {
+	for _, value := range values {
+		scope := db.NewScope(value)
+		if !IsTranslatable(scope) {
+			return fmt.Errorf("%v is not translatable", scope.GetModelStruct().ModelType.Name())
+		}
+
+		var structFields []reflect.StructField
+		for _, field := range scope.GetModelStruct().PrimaryFields {
+			structFields = append(structFields, reflect.StructField{
+				Name: field.Name,
+				Type: field.Struct.Type,
+				Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%v;primary_key"`, field.DBName)),
+			})
+		}
+
+		structFields = append(structFields, reflect.StructField{
+			Name: "LanguageCode",
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`sql:"size:20" gorm:"column:%v;primary_key"`, LanguageColumn(scope))),
+		})
+
+		for _, field := range translateFields(scope) {
+			structFields = append(structFields, reflect.StructField{
+				Name: field.Name,
+				Type: field.Struct.Type,
+				Tag:  reflect.StructTag(fmt.Sprintf(`gorm:"column:%v" sql:"%v"`, field.DBName, field.Tag.Get("sql"))),
+			})
+		}
+
+		translation := reflect.New(reflect.StructOf(structFields)).Interface()
+		if err := db.Table(TranslationTableName(scope)).AutoMigrate(translation).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func beforeQueryTranslations(scope *gorm.Scope) {
+	mode, err := getMode(scope)
+	if err != nil {
+		scope.Err(err)
+		return
+	}
+
+	var (
+		quotedTableName            = scope.QuotedTableName()
+		quotedTranslationTableName = scope.Quote(TranslationTableName(scope))
+		quotedLanguageColumn       = scope.Quote(LanguageColumn(scope))
+		locale, isLocale           = getQueryLocale(scope)
+		hasSelects                 = len(scope.SelectAttrs()) > 0
+		joinTranslation            = func(alias string, locale string, join string) {
+			var conditions []string
+			for _, field := range scope.PrimaryFields() {
+				conditions = append(conditions, fmt.Sprintf("%v.%v = %v.%v", alias, scope.Quote(field.DBName), quotedTableName, scope.Quote(field.DBName)))
+			}
+			scope.Search.Joins(fmt.Sprintf("%v JOIN %v %v ON %v AND %v.%v = ?", join, quotedTranslationTableName, alias, strings.Join(conditions, " AND "), alias, quotedLanguageColumn), locale)
+		}
+		selectColumns = func(expression func(field *gorm.StructField) string, localeExpression string) {
+			if hasSelects {
+				selectTranslatedColumns(scope, expression)
+				return
+			}
+
+			var columns []string
+			for _, field := range scope.GetModelStruct().StructFields {
+				if field.IsNormal && !field.IsIgnored {
+					if isTranslateField(field) && !field.IsPrimaryKey {
+						columns = append(columns, fmt.Sprintf("%v AS %v", expression(field), scope.Quote(field.DBName)))
+					} else {
+						columns = append(columns, fmt.Sprintf("%v.%v", quotedTableName, scope.Quote(field.DBName)))
+					}
+				}
+			}
+			columns = append(columns, fmt.Sprintf("%v AS translation_locale", localeExpression))
+			scope.Search.Select(strings.Join(columns, ", "))
+		}
+	)
+
+	switch mode {
+	case ModeUnscoped, ModeGlobal:
+	case ModeStale:
+		scope.Err(fmt.Errorf("l10n: %v doesn't track revisions", scope.GetModelStruct().ModelType.Name()))
+	case ModeHidden:
+		scope.Err(fmt.Errorf("l10n: %v can't be hidden", scope.GetModelStruct().ModelType.Name()))
+	case ModeLocale:
+		if isLocale {
+			joinTranslation("l10n_tr0", locale, "INNER")
+			selectColumns(func(field *gorm.StructField) string {
+				// columns that haven't been translated fall back to global values
+				return fmt.Sprintf("COALESCE(l10n_tr0.%v, %v.%v)", scope.Quote(field.DBName), quotedTableName, scope.Quote(field.DBName))
+			}, "l10n_tr0."+quotedLanguageColumn)
+		}
+	case ModeReverse:
+		joinTranslation("l10n_tr0", locale, "LEFT")
+		scope.Search.Where(fmt.Sprintf("l10n_tr0.%v IS NULL", quotedLanguageColumn))
+	case ModeFallback:
+		if isLocale {
+			var aliases []string
+			config := getConfig(scope)
+			for _, fallback := range append([]string{locale}, config.FallbackLocales(locale)...) {
+				if fallback != config.GlobalLocale {
+					alias := fmt.Sprintf("l10n_tr%v", len(aliases))
+					joinTranslation(alias, fallback, "LEFT")
+					aliases = append(aliases, alias)
+				}
+			}
+
+			// use the translation from the most specific locale in the fallback chain
+			var localeExpressions []string
+			for _, alias := range aliases {
+				localeExpressions = append(localeExpressions, alias+"."+quotedLanguageColumn)
+			}
+
+			selectColumns(func(field *gorm.StructField) string {
+				var expression = "CASE"
+				for _, alias := range aliases {
+					expression += fmt.Sprintf(" WHEN %v.%v IS NOT NULL THEN COALESCE(%v.%v, %v.%v)", alias, quotedLanguageColumn, alias, scope.Quote(field.DBName), quotedTableName, scope.Quote(field.DBName))
+				}
+				return expression + fmt.Sprintf(" ELSE %v.%v END", quotedTableName, scope.Quote(field.DBName))
+			}, fmt.Sprintf("COALESCE(%v, NULL)", strings.Join(localeExpressions, ", ")))
+		}
+	}
+}
+
+var plainColumnRegexp = regexp.MustCompile("^[`\"]?(?:(\\w+)[`\"]?\\.[`\"]?)?(\\w+)[`\"]?$")
+
+// selectTranslatedColumns replace plain columns selected with `Select` or `Pluck` with their translated values, e.g: Pluck("name"), other expressions like "DISTINCT name" read the main table's global values
+func selectTranslatedColumns(scope *gorm.Scope, expression func(field *gorm.StructField) string) {
+	var columns []string
+	for _, attr := range scope.SelectAttrs() {
+		if strings.Contains(attr, "?") {
+			// selects with arguments are kept as they are
+			return
+		}
+
+		for _, column := range strings.Split(attr, ",") {
+			column = strings.TrimSpace(column)
+			if matches := plainColumnRegexp.FindStringSubmatch(column); matches != nil && (matches[1] == "" || matches[1] == scope.TableName()) {
+				column = fmt.Sprintf("%v.%v", scope.QuotedTableName(), scope.Quote(matches[2]))
+				if field, ok := scope.FieldByName(matches[2]); ok && isTranslateField(field.StructField) && !field.IsPrimaryKey {
+					column = fmt.Sprintf("%v AS %v", expression(field.StructField), scope.Quote(field.DBName))
+				}
+			}
+			columns = append(columns, column)
+		}
+	}
+	scope.Search.Select(strings.Join(columns, ", "))
+}
+
+func beforeSaveTranslations(scope *gorm.Scope) {
+	if IsTranslatable(scope) {
+		if isPseudoLocale(scope) {
+			scope.Err(fmt.Errorf("l10n: pseudo locale %v is read only", PseudoLocale))
+			scope.SkipLeft()
+			return
+		}
+
+		if locale, isLocale := getLocale(scope); isLocale {
+			if err := validateLocale(getConfig(scope), locale); err != nil {
+				scope.Err(err)
+				scope.SkipLeft()
+				return
+			}
+			saveTranslation(scope, locale)
+		}
+	}
+}
+
+func beforeDeleteTranslations(scope *gorm.Scope) {
+	if IsTranslatable(scope) {
+		if isPseudoLocale(scope) {
+			scope.Err(fmt.Errorf("l10n: pseudo locale %v is read only", PseudoLocale))
+			scope.SkipLeft()
+			return
+		}
+
+		if locale, isLocale := getQueryLocale(scope); isLocale {
+			deleteTranslation(scope, locale)
+		}
+	}
+}
+
+// saveTranslation save translatable fields into the translation table for locale, and skip saving the record itself
+func saveTranslation(scope *gorm.Scope, locale string) {
+	if scope.PrimaryKeyZero() {
+		scope.Err(fmt.Errorf("the resource %v cannot be created in %v", scope.GetModelStruct().ModelType.Name(), locale))
+		return
+	}
+
+	var (
+		columns            []string
+		values             []interface{}
+		conditions         []string
+		primaryValues      []interface{}
+		updateAttrs, _     = scope.InstanceGet("gorm:update_attrs")
+		attrs, hasAttrs    = updateAttrs.(map[string]interface{})
+		quotedTranslations = scope.Quote(TranslationTableName(scope))
+	)
+
+	for _, field := range translateFields(scope) {
+		if hasAttrs {
+			if value, ok := attrs[field.DBName]; ok {
+				columns = append(columns, scope.Quote(field.DBName))
+				values = append(values, value)
+			}
+		} else if field, ok := scope.FieldByName(field.Name); ok {
+			columns = append(columns, scope.Quote(field.DBName))
+			values = append(values, field.Field.Interface())
+		}
+	}
+
+	for _, field := range scope.PrimaryFields() {
+		conditions = append(conditions, fmt.Sprintf("%v = ?", scope.Quote(field.DBName)))
+		primaryValues = append(primaryValues, field.Field.Interface())
+	}
+	conditions = append(conditions, fmt.Sprintf("%v = ?", scope.Quote(LanguageColumn(scope))))
+	primaryValues = append(primaryValues, locale)
+
+	var count int
+	if err := scope.NewDB().Table(TranslationTableName(scope)).Where(strings.Join(conditions, " AND "), primaryValues...).Count(&count).Error; err != nil {
+		scope.Err(err)
+		return
+	}
+
+	db := scope.NewDB()
+	if count > 0 {
+		if len(columns) > 0 {
+			db = db.Exec(fmt.Sprintf("UPDATE %v SET %v = ? WHERE %v", quotedTranslations, strings.Join(columns, " = ?, "), strings.Join(conditions, " AND ")), append(values, primaryValues...)...)
+		}
+	} else {
+		// columns that are not updated are left NULL, so they fall back to the global record's current values
+		for _, field := range scope.PrimaryFields() {
+			columns = append(columns, scope.Quote(field.DBName))
+		}
+		columns = append(columns, scope.Quote(LanguageColumn(scope)))
+		values = append(values, primaryValues...)
+		db = db.Exec(fmt.Sprintf("INSERT INTO %v (%v) VALUES (%v)", quotedTranslations, strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")), values...)
+	}
+
+	if scope.Err(db.Error) == nil {
+		scope.DB().RowsAffected = 1
+		if field, ok := scope.FieldByName("TranslationLocale"); ok {
+			field.Set(locale)
+		}
+	}
+	scope.SkipLeft()
+}
+
+// afterDeleteTranslations delete translations of global records that have been deleted permanently, so they won't be attached to new records reusing their primary keys, soft deleted records keep translations to be restored
+func afterDeleteTranslations(scope *gorm.Scope) {
+	if scope.HasError() || !IsTranslatable(scope) || (scope.HasColumn("DeletedAt") && !scope.Search.Unscoped) {
+		return
+	}
+
+	if _, isLocale := getQueryLocale(scope); isLocale {
+		return
+	}
+
+	var (
+		quotedTranslations = scope.Quote(TranslationTableName(scope))
+		quotedPrimaryKeys  []string
+		conditions         []string
+		primaryValues      []interface{}
+	)
+
+	for _, field := range scope.PrimaryFields() {
+		quotedPrimaryKeys = append(quotedPrimaryKeys, scope.Quote(field.DBName))
+		if !scope.PrimaryKeyZero() {
+			conditions = append(conditions, fmt.Sprintf("%v.%v = ?", quotedTranslations, scope.Quote(field.DBName)))
+			primaryValues = append(primaryValues, field.Field.Interface())
+		}
+	}
+
+	// records deleted with conditions are unknown, delete all translations without global records
+	conditions = append(conditions, fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %v WHERE %v)", scope.QuotedTableName(), primaryKeysJoinCondition(scope.QuotedTableName(), quotedTranslations, quotedPrimaryKeys)))
+	scope.Err(scope.NewDB().Exec(fmt.Sprintf("DELETE FROM %v WHERE %v", quotedTranslations, strings.Join(conditions, " AND ")), primaryValues...).Error)
+}
+
+// deleteTranslation delete the record's translation for locale, and skip deleting the record itself
+func deleteTranslation(scope *gorm.Scope, locale string) {
+	if scope.PrimaryKeyZero() {
+		scope.Err(errors.New("translations can only be deleted with primary key"))
+		return
+	}
+
+	var (
+		conditions    []string
+		primaryValues []interface{}
+	)
+
+	for _, field := range scope.PrimaryFields() {
+		conditions = append(conditions, fmt.Sprintf("%v = ?", scope.Quote(field.DBName)))
+		primaryValues = append(primaryValues, field.Field.Interface())
+	}
+
+	db := scope.NewDB().Exec(fmt.Sprintf("DELETE FROM %v WHERE %v AND %v = ?", scope.Quote(TranslationTableName(scope)), strings.Join(conditions, " AND "), scope.Quote(LanguageColumn(scope))), append(primaryValues, locale)...)
+	if scope.Err(db.Error) == nil {
+		scope.DB().RowsAffected = db.RowsAffected
+	}
+	scope.SkipLeft()
+}
@@ -0,0 +1,213 @@
+package l10n
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TranslationMemory remember translations of texts, built from existing translations of localizable and translatable models, to reuse them when translating records. It implements Translator, so could be used with `WithTranslator` or as `DefaultTranslator`, e.g:
+//
+//	memory, err := l10n.BuildTranslationMemory(db, &Product{}, &Brand{})
+//	l10n.DefaultTranslator = memory
+type TranslationMemory struct {
+	// MinScore minimum similarity (0 - 1) of fuzzy matches, only exact matches are used if it is 1
+	MinScore float64
+	// Fallback translator used to translate texts that have no matches
+	Fallback Translator
+
+	mutex        sync.RWMutex
+	translations map[translationMemoryPair]map[string]map[string]int
+}
+
+type translationMemoryPair struct {
+	From string
+	To   string
+}
+
+// TranslationSuggestion a suggested translation from translation memory
+type TranslationSuggestion struct {
+	Source string
+	Target string
+	// Score similarity between the text and source, 1 means exact match
+	Score float64
+	// Count how many times the target has been used for the source
+	Count int
+}
+
+// NewTranslationMemory initialize an empty translation memory
+func NewTranslationMemory() *TranslationMemory {
+	return &TranslationMemory{MinScore: 0.8, translations: map[translationMemoryPair]map[string]map[string]int{}}
+}
+
+// BuildTranslationMemory build translation memory from global values and their translations of models
+func BuildTranslationMemory(db *gorm.DB, models ...interface{}) (*TranslationMemory, error) {
+	memory := NewTranslationMemory()
+	return memory, memory.Index(db, models...)
+}
+
+// Index add global values and their translations in all locales of models into the memory
+func (memory *TranslationMemory) Index(db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		var (
+			scope    = db.NewScope(model)
+			localeDB = newDB(db)
+			global   = GetConfig(db).GlobalLocale
+		)
+
+		if IsTranslatable(scope) {
+			localeDB = localeDB.Table(TranslationTableName(scope))
+		} else {
+			localeDB = WithMode(localeDB, ModeUnscoped).Model(model)
+		}
+
+		column := scope.Quote(LanguageColumn(scope))
+		locales, err := pluckLocales(localeDB.Where(fmt.Sprintf("%v <> ?", column), LocaleValue(scope, global)), languageType(scope), column)
+		if err != nil {
+			return err
+		}
+
+		for _, locale := range locales {
+			units, err := ExportTranslationUnits(db, model, locale)
+			if err != nil {
+				return err
+			}
+
+			for _, unit := range units {
+				// untranslated values copied from global are not remembered
+				if unit.Target != "" && unit.Target != unit.Source {
+					memory.Add(global, locale, unit.Source, unit.Target)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Add add a translation into the memory
+func (memory *TranslationMemory) Add(from, to, source, target string) {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	if memory.translations == nil {
+		memory.translations = map[translationMemoryPair]map[string]map[string]int{}
+	}
+
+	pair := translationMemoryPair{From: from, To: to}
+	if memory.translations[pair] == nil {
+		memory.translations[pair] = map[string]map[string]int{}
+	}
+
+	if memory.translations[pair][source] == nil {
+		memory.translations[pair][source] = map[string]int{}
+	}
+	memory.translations[pair][source][target]++
+}
+
+// Suggest return translations for text whose source is similar to it, exact and most used ones first
+func (memory *TranslationMemory) Suggest(from, to, text string) (suggestions []TranslationSuggestion) {
+	memory.mutex.RLock()
+	defer memory.mutex.RUnlock()
+
+	for source, targets := range memory.translations[translationMemoryPair{From: from, To: to}] {
+		score := 1.0
+		if source != text {
+			if score = similarity(source, text); score < memory.MinScore || memory.MinScore >= 1 {
+				continue
+			}
+		}
+
+		for target, count := range targets {
+			suggestions = append(suggestions, TranslationSuggestion{Source: source, Target: target, Score: score, Count: count})
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Target < suggestions[j].Target
+	})
+	return
+}
+
+// Translate translate texts with their best suggestions, texts without suggestions are translated with the fallback translator if there is, otherwise they are kept as they are
+func (memory *TranslationMemory) Translate(from, to string, texts []string) ([]string, error) {
+	var (
+		results   = make([]string, len(texts))
+		missing   []string
+		missingAt []int
+	)
+
+	for idx, text := range texts {
+		if suggestions := memory.Suggest(from, to, text); len(suggestions) > 0 {
+			results[idx] = suggestions[0].Target
+		} else {
+			results[idx] = text
+			missing = append(missing, text)
+			missingAt = append(missingAt, idx)
+		}
+	}
+
+	if memory.Fallback != nil && len(missing) > 0 {
+		translations, err := memory.Fallback.Translate(from, to, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx, translation := range translations {
+			if idx < len(missingAt) {
+				results[missingAt[idx]] = translation
+			}
+		}
+	}
+	return results, nil
+}
+
+// similarity return similarity of two strings based on their levenshtein distance, 1 means they are same
+func similarity(a, b string) float64 {
+	var (
+		ra, rb    = []rune(a), []rune(b)
+		maxLength = len(ra)
+	)
+
+	if len(rb) > maxLength {
+		maxLength = len(rb)
+	}
+
+	if maxLength == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(maxLength)
+}
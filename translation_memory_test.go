@@ -0,0 +1,36 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestTranslationMemory(t *testing.T) {
+	product := Product{Code: "TranslationMemory", Name: "Machine washable cotton"}
+	dbGlobal.Create(&product)
+	product.Name = "可机洗棉"
+	dbCN.Create(&product)
+
+	memory, err := l10n.BuildTranslationMemory(dbGlobal, &Product{})
+	checkHasErr(t, err)
+
+	if suggestions := memory.Suggest(l10n.Global, "zh", "Machine washable cotton"); len(suggestions) == 0 || suggestions[0].Target != "可机洗棉" || suggestions[0].Score != 1 {
+		t.Errorf("should suggest exact match, but got %#v", suggestions)
+	}
+
+	if suggestions := memory.Suggest(l10n.Global, "zh", "Machine washable cottons"); len(suggestions) == 0 || suggestions[0].Target != "可机洗棉" || suggestions[0].Score >= 1 {
+		t.Errorf("should suggest fuzzy match, but got %#v", suggestions)
+	}
+
+	if suggestions := memory.Suggest(l10n.Global, "zh", "Dry clean only"); len(suggestions) != 0 {
+		t.Errorf("should not suggest dissimilar texts, but got %#v", suggestions)
+	}
+
+	memory.Fallback = l10n.DictionaryTranslator{"zh": {"Dry clean only": "仅限干洗"}}
+	newProduct := Product{Code: "TranslationMemory2", Name: "Machine washable cotton", Description: "Dry clean only"}
+	checkHasErr(t, l10n.TranslateRecord(l10n.WithTranslator(dbGlobal, memory), &newProduct, l10n.Global, "zh"))
+	if newProduct.Name != "可机洗棉" || newProduct.Description != "仅限干洗" {
+		t.Errorf("should translate with translation memory and its fallback, but got %#v", newProduct)
+	}
+}
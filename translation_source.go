@@ -0,0 +1,59 @@
+package l10n
+
+import (
+	"encoding/json"
+	"html/template"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TranslationSource source values of a record shown beside inputs when translating it in Qor Admin
+type TranslationSource struct {
+	// Locale locale of the source values
+	Locale string
+	// Locales locales that could be used as source
+	Locales []string
+	// Values source values of translatable fields, keyed by field name
+	Values map[string]string
+	// SyncFields names of sync fields, they are locked when editing localized records
+	SyncFields []string
+}
+
+// JSON return the source as JSON, used by l10n.js to render source values
+func (source TranslationSource) JSON() template.JS {
+	results, _ := json.Marshal(source)
+	return template.JS(results)
+}
+
+// GetTranslationSource load values of record in source locale, used as source when translating the record
+func GetTranslationSource(db *gorm.DB, record interface{}, locale string) (*TranslationSource, error) {
+	var (
+		scope  = db.NewScope(record)
+		source = &TranslationSource{Locale: locale, Values: map[string]string{}}
+	)
+
+	for _, field := range scope.GetModelStruct().StructFields {
+		if field.IsNormal && isSyncField(field) {
+			source.SyncFields = append(source.SyncFields, field.Name)
+		}
+	}
+
+	// fall back to global values if the record hasn't been localized in source locale
+	sourceRecord, err := findLocalizedRecord(db, record, locale)
+	if global := GetConfig(db).GlobalLocale; err == nil && sourceRecord == nil && locale != global {
+		sourceRecord, err = findLocalizedRecord(db, record, global)
+		source.Locale = global
+	}
+
+	if err != nil || sourceRecord == nil {
+		return nil, err
+	}
+
+	sourceScope := db.NewScope(sourceRecord)
+	for _, structField := range exchangeFields(scope) {
+		if field, ok := sourceScope.FieldByName(structField.Name); ok {
+			source.Values[field.Name] = field.Field.String()
+		}
+	}
+	return source, nil
+}
@@ -0,0 +1,30 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestGetTranslationSource(t *testing.T) {
+	product := Product{Code: "TranslationSource", Name: "global name", Description: "global description"}
+	dbGlobal.Create(&product)
+	product.Name = "中文名"
+	dbCN.Create(&product)
+
+	source, err := l10n.GetTranslationSource(dbGlobal, &product, "zh")
+	checkHasErr(t, err)
+	if source.Locale != "zh" || source.Values["Name"] != "中文名" || len(source.SyncFields) == 0 {
+		t.Errorf("should load source values from source locale, but got %#v", source)
+	}
+
+	if _, ok := source.Values["Code"]; ok {
+		t.Errorf("should not include sync fields in source values")
+	}
+
+	source, err = l10n.GetTranslationSource(dbGlobal, &product, "ja")
+	checkHasErr(t, err)
+	if source.Locale != l10n.Global || source.Values["Name"] != "global name" {
+		t.Errorf("should fall back to global values, but got %#v", source)
+	}
+}
@@ -0,0 +1,156 @@
+package l10n_test
+
+import (
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestTranslatable(t *testing.T) {
+	article := Article{Code: "Translatable", Title: "global title", Body: "global body"}
+	checkHasErr(t, dbGlobal.Create(&article).Error)
+
+	article.Title = "中文标题"
+	article.Body = "中文内容"
+	article.Code = "should be ignored when saving translation"
+	checkHasErr(t, dbCN.Save(&article).Error)
+
+	var count int
+	if dbGlobal.Table("articles_translations").Where("id = ? AND language_code = ?", article.ID, "zh").Count(&count); count != 1 {
+		t.Errorf("should save translation into translation table, but found %v", count)
+	}
+
+	var globalArticle Article
+	dbGlobal.First(&globalArticle, article.ID)
+	if globalArticle.Title != "global title" || globalArticle.Code != "Translatable" || globalArticle.TranslationLocale != "" {
+		t.Errorf("global article should not be changed, but got %#v", globalArticle)
+	}
+
+	var articleCN Article
+	dbCN.First(&articleCN, article.ID)
+	if articleCN.Title != "中文标题" || articleCN.Body != "中文内容" || articleCN.Code != "Translatable" || articleCN.TranslationLocale != "zh" {
+		t.Errorf("should find translated article, but got %#v", articleCN)
+	}
+
+	var articleEN Article
+	dbEN.First(&articleEN, article.ID)
+	if articleEN.Title != "global title" || articleEN.TranslationLocale != "" {
+		t.Errorf("should fall back to global article, but got %#v", articleEN)
+	}
+
+	if !l10n.WithMode(dbEN, l10n.ModeLocale).First(&Article{}, article.ID).RecordNotFound() {
+		t.Errorf("should find no translated article with locale mode")
+	}
+
+	if l10n.WithMode(dbCN, l10n.ModeLocale).First(&Article{}, article.ID).RecordNotFound() {
+		t.Errorf("should find translated article with locale mode")
+	}
+
+	if l10n.WithMode(dbEN, l10n.ModeReverse).Model(&Article{}).Where("code = ?", "Translatable").Count(&count); count != 1 {
+		t.Errorf("should find untranslated article with reverse mode")
+	}
+
+	if l10n.WithMode(dbCN, l10n.ModeReverse).Model(&Article{}).Where("code = ?", "Translatable").Count(&count); count != 0 {
+		t.Errorf("should find no untranslated article with reverse mode")
+	}
+
+	// update translation
+	articleCN.Title = "新的中文标题"
+	checkHasErr(t, dbCN.Save(&articleCN).Error)
+	checkHasErr(t, dbCN.Model(&articleCN).Updates(map[string]interface{}{"body": "新的中文内容"}).Error)
+
+	var newArticleCN Article
+	dbCN.First(&newArticleCN, article.ID)
+	if newArticleCN.Title != "新的中文标题" || newArticleCN.Body != "新的中文内容" {
+		t.Errorf("should update translation, but got %#v", newArticleCN)
+	}
+
+	// delete translation
+	checkHasErr(t, dbCN.Delete(&newArticleCN).Error)
+	var deletedArticleCN Article
+	dbCN.First(&deletedArticleCN, article.ID)
+	if deletedArticleCN.Title != "global title" {
+		t.Errorf("should delete translation only, but got %#v", deletedArticleCN)
+	}
+}
+
+func TestTranslatableWithFallbackLocales(t *testing.T) {
+	l10n.Fallbacks["zh-HK"] = []string{"zh-TW", "zh"}
+	defer delete(l10n.Fallbacks, "zh-HK")
+
+	article := Article{Code: "TranslatableFallback", Title: "global title"}
+	dbGlobal.Create(&article)
+	article.Title = "zh title"
+	dbCN.Save(&article)
+
+	var articleHK Article
+	l10n.WithLocale(dbGlobal, "zh-HK").First(&articleHK, article.ID)
+	if articleHK.Title != "zh title" || articleHK.TranslationLocale != "zh" {
+		t.Errorf("should fall back to zh translation, but got %#v", articleHK)
+	}
+
+	if err := dbCN.Create(&Article{Code: "TranslatableFallback2"}).Error; err == nil {
+		t.Errorf("should not be able to create translatable record in locale")
+	}
+}
+
+func TestTranslatableUpdateColumns(t *testing.T) {
+	article := Article{Code: "TranslatableUpdate", Title: "global title", Body: "global body"}
+	dbGlobal.Create(&article)
+	checkHasErr(t, dbCN.Model(&article).Update("title", "中文标题").Error)
+
+	var articleCN Article
+	dbCN.First(&articleCN, article.ID)
+	if articleCN.Title != "中文标题" || articleCN.Body != "global body" {
+		t.Errorf("columns that haven't been translated should fall back to global values, but got %#v", articleCN)
+	}
+
+	// untranslated columns follow later changes of the global record
+	checkHasErr(t, dbGlobal.Model(&article).Update("body", "global body updated").Error)
+	dbCN.First(&articleCN, article.ID)
+	if articleCN.Title != "中文标题" || articleCN.Body != "global body updated" {
+		t.Errorf("untranslated columns should follow the global record, but got %#v", articleCN)
+	}
+
+	var articleLocale Article
+	dbCN.Set("l10n:mode", "locale").First(&articleLocale, article.ID)
+	if articleLocale.Title != "中文标题" || articleLocale.Body != "global body updated" {
+		t.Errorf("columns that haven't been translated should fall back to global values in locale mode, but got %#v", articleLocale)
+	}
+
+	var titles []string
+	dbCN.Model(&Article{}).Where("code = ?", "TranslatableUpdate").Pluck("title", &titles)
+	if len(titles) != 1 || titles[0] != "中文标题" {
+		t.Errorf("should pluck translated values, but got %#v", titles)
+	}
+
+	var selected Article
+	dbCN.Select("id, code, title").First(&selected, article.ID)
+	if selected.Title != "中文标题" || selected.Code != "TranslatableUpdate" || selected.Body != "" {
+		t.Errorf("should select translated values, but got %#v", selected)
+	}
+}
+
+func TestDeleteTranslatable(t *testing.T) {
+	article := Article{Code: "TranslatableDelete", Title: "global title"}
+	dbGlobal.Create(&article)
+	article.Title = "中文标题"
+	dbCN.Save(&article)
+
+	checkHasErr(t, dbGlobal.Delete(&article).Error)
+
+	var count int
+	dbGlobal.Table("articles_translations").Where("id = ?", article.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("translations should be deleted with the global record, but got %v", count)
+	}
+
+	reused := Article{ID: article.ID, Code: "TranslatableDelete", Title: "new title"}
+	checkHasErr(t, dbGlobal.Create(&reused).Error)
+
+	var reusedCN Article
+	dbCN.First(&reusedCN, reused.ID)
+	if reusedCN.Title != "new title" || reusedCN.TranslationLocale != "" {
+		t.Errorf("records reusing the primary key should not get translations of deleted records, but got %#v", reusedCN)
+	}
+}
@@ -0,0 +1,168 @@
+package l10n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Translator translate texts from one locale to another, used to pre-fill translations when localizing records
+type Translator interface {
+	Translate(from, to string, texts []string) ([]string, error)
+}
+
+// DefaultTranslator translator used when localizing records if no translator set with `WithTranslator`, records are copied verbatim if it is nil
+var DefaultTranslator Translator
+
+// WithTranslator set translator used to translate records when localizing them
+func WithTranslator(db *gorm.DB, translator Translator) *gorm.DB {
+	return db.Set("l10n:translator", translator)
+}
+
+func getTranslator(db *gorm.DB) Translator {
+	if value, ok := db.Get("l10n:translator"); ok {
+		if translator, ok := value.(Translator); ok {
+			return translator
+		}
+	}
+	return DefaultTranslator
+}
+
+// DictionaryTranslator translate texts with a dictionary, keyed by target locale and source text, texts not in the dictionary are kept as they are, e.g:
+//
+//	l10n.DictionaryTranslator{"zh-CN": {"Machine washable": "可机洗"}}
+type DictionaryTranslator map[string]map[string]string
+
+// LoadDictionaryTranslator load dictionary translator from a JSON file, which has the same structure with DictionaryTranslator
+func LoadDictionaryTranslator(path string) (DictionaryTranslator, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary := DictionaryTranslator{}
+	return dictionary, json.Unmarshal(content, &dictionary)
+}
+
+// Translate translate texts with the dictionary of target locale
+func (dictionary DictionaryTranslator) Translate(from, to string, texts []string) ([]string, error) {
+	results := make([]string, len(texts))
+	for idx, text := range texts {
+		if translation, ok := dictionary[to][text]; ok {
+			results[idx] = translation
+		} else {
+			results[idx] = text
+		}
+	}
+	return results, nil
+}
+
+// HTTPTranslator translate texts with a HTTP service, it posts JSON `{"from": "en-US", "to": "zh-CN", "texts": ["..."]}` to URL, and expects JSON `{"texts": ["..."]}` in the same order
+type HTTPTranslator struct {
+	URL    string
+	Header http.Header
+	// Client HTTP client used to call the service, default client times out after 30 seconds, so localizing isn't blocked by an unresponsive service
+	Client *http.Client
+}
+
+var defaultTranslatorClient = &http.Client{Timeout: 30 * time.Second}
+
+type httpTranslatorRequest struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Texts []string `json:"texts"`
+}
+
+type httpTranslatorResponse struct {
+	Texts []string `json:"texts"`
+}
+
+// Translate translate texts with the HTTP service
+func (translator *HTTPTranslator) Translate(from, to string, texts []string) ([]string, error) {
+	body, err := json.Marshal(httpTranslatorRequest{From: from, To: to, Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", translator.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range translator.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := translator.Client
+	if client == nil {
+		client = defaultTranslatorClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("l10n: translator returned %v", resp.Status)
+	}
+
+	var result httpTranslatorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Texts) != len(texts) {
+		return nil, fmt.Errorf("l10n: translator returned %v texts, expected %v", len(result.Texts), len(texts))
+	}
+	return result.Texts, nil
+}
+
+// TranslateRecord translate translatable string fields of record from a locale to another with DB's translator, sync fields and blank values are kept, does nothing if there is no translator
+func TranslateRecord(db *gorm.DB, record interface{}, from, to string) error {
+	translator := getTranslator(db)
+	if translator == nil || from == to {
+		return nil
+	}
+
+	var (
+		scope  = db.NewScope(record)
+		fields []*gorm.Field
+		texts  []string
+	)
+
+	for _, structField := range exchangeFields(scope) {
+		if field, ok := scope.FieldByName(structField.Name); ok && field.Field.String() != "" {
+			fields = append(fields, field)
+			texts = append(texts, field.Field.String())
+		}
+	}
+
+	if len(texts) == 0 {
+		return nil
+	}
+
+	results, err := translator.Translate(from, to, texts)
+	if err != nil {
+		return err
+	}
+
+	if len(results) != len(texts) {
+		return fmt.Errorf("l10n: translator returned %v texts, expected %v", len(results), len(texts))
+	}
+
+	for idx, field := range fields {
+		field.Field.Set(reflect.ValueOf(results[idx]).Convert(field.Field.Type()))
+	}
+	return nil
+}
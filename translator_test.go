@@ -0,0 +1,65 @@
+package l10n_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/qor/l10n"
+)
+
+func TestDictionaryTranslator(t *testing.T) {
+	file, err := ioutil.TempFile("", "l10n_dictionary")
+	checkHasErr(t, err)
+	defer os.Remove(file.Name())
+	file.WriteString(`{"zh": {"Machine washable": "可机洗"}}`)
+	file.Close()
+
+	translator, err := l10n.LoadDictionaryTranslator(file.Name())
+	checkHasErr(t, err)
+
+	results, err := translator.Translate(l10n.Global, "zh", []string{"Machine washable", "Unknown"})
+	checkHasErr(t, err)
+	if strings.Join(results, ",") != "可机洗,Unknown" {
+		t.Errorf("should translate texts with dictionary, but got %v", results)
+	}
+}
+
+func TestTranslateRecord(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			From  string
+			To    string
+			Texts []string
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		for idx, text := range body.Texts {
+			body.Texts[idx] = body.To + ":" + text
+		}
+		json.NewEncoder(w).Encode(map[string][]string{"texts": body.Texts})
+	}))
+	defer server.Close()
+
+	product := Product{Code: "TranslateRecord", Name: "global name"}
+	dbGlobal.Create(&product)
+
+	db := l10n.WithTranslator(dbGlobal, &l10n.HTTPTranslator{URL: server.URL})
+	checkHasErr(t, l10n.TranslateRecord(db, &product, l10n.Global, "zh"))
+	if product.Name != "zh:global name" || product.Description != "" || product.Code != "TranslateRecord" {
+		t.Errorf("should translate translatable fields only, but got %#v", product)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	db = l10n.WithTranslator(dbGlobal, &l10n.HTTPTranslator{URL: failing.URL})
+	if err := l10n.TranslateRecord(db, &product, l10n.Global, "zh"); err == nil {
+		t.Errorf("should return error if translator failed")
+	}
+}
@@ -2,8 +2,44 @@ $(function () {
 
   'use strict';
 
-  $('.qor-locales').on('change', function () {
+  $('.qor-locales, .qor-translation-sources').on('change', function () {
     window.location.assign($(this).val());
   });
 
+  // show source values beside translatable fields when editing localized records
+  var $source = $('[data-l10n-source]');
+
+  if (!$source.length) {
+    return;
+  }
+
+  var source = $source.data('l10n-source') || {};
+  var copyText = $source.data('copy-text');
+
+  $.each(source.Values || {}, function (name, value) {
+    var $input = $('[name="QorResource.' + name + '"]').first();
+    var $field = $input.closest('.qor-field');
+    var $panel, $button;
+
+    if (!$input.length || !$field.length) {
+      return;
+    }
+
+    $panel = $('<div class="qor-l10n-source"></div>');
+    $panel.append($('<span class="qor-l10n-source__locale"></span>').text(source.Locale));
+    $panel.append($('<div class="qor-l10n-source__value"></div>').text(value));
+
+    $button = $('<button type="button" class="mdl-button mdl-button--primary qor-l10n-source__copy"></button>').text(copyText);
+    $button.on('click', function () {
+      $input.val(value).trigger('change');
+    });
+    $panel.append($button);
+
+    $field.addClass('qor-l10n-translatable').append($panel);
+  });
+
+  $.each(source.SyncFields || [], function (index, name) {
+    $('[name="QorResource.' + name + '"]').prop('readonly', true).closest('.qor-field').addClass('qor-l10n-locked');
+  });
+
 });
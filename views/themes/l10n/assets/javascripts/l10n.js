@@ -1 +1 @@
-$(function(){"use strict";$(".qor-locales").on("change",function(){window.location.assign($(this).val())})});
\ No newline at end of file
+$(function(){"use strict";$(".qor-locales, .qor-translation-sources").on("change",function(){window.location.assign($(this).val())});var e=$("[data-l10n-source]");if(e.length){var o=e.data("l10n-source")||{},a=e.data("copy-text");$.each(o.Values||{},function(e,l){var n,t,r=$('[name="QorResource.'+e+'"]').first(),c=r.closest(".qor-field");r.length&&c.length&&(n=$('<div class="qor-l10n-source"></div>'),n.append($('<span class="qor-l10n-source__locale"></span>').text(o.Locale)),n.append($('<div class="qor-l10n-source__value"></div>').text(l)),t=$('<button type="button" class="mdl-button mdl-button--primary qor-l10n-source__copy"></button>').text(a),t.on("click",function(){r.val(l).trigger("change")}),n.append(t),c.addClass("qor-l10n-translatable").append(n))}),$.each(o.SyncFields||[],function(e,o){$('[name="QorResource.'+o+'"]').prop("readonly",!0).closest(".qor-field").addClass("qor-l10n-locked")})}});
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+	"github.com/qor/admin"
+	"github.com/qor/l10n"
+	"github.com/qor/qor"
+	"github.com/qor/qor/resource"
+	"github.com/qor/roles"
+	"github.com/qor/worker"
+)
+
+// jobKey key of registered jobs, job names are unique in a worker
+type jobKey struct {
+	worker *worker.Worker
+	name   string
+}
+
+var (
+	registeredJobs = map[jobKey]*admin.Resource{}
+	registeredLogs = map[*admin.Admin]bool{}
+	registerMutex  sync.Mutex
+)
+
+func init() {
+	// register jobs automatically for admins that have added a worker before localizable resources
+	l10n.RegisterResourceConfigurator(func(res *admin.Resource) {
+		for _, workerRes := range res.GetAdmin().GetResources() {
+			if Worker, ok := workerRes.Value.(*worker.Worker); ok {
+				registerLog(Worker, res.GetAdmin())
+				registerJobs(Worker, res.GetAdmin(), res)
+			}
+		}
+	})
+}
+
+// LocalizeArgument argument of localize jobs
+type LocalizeArgument struct {
+	l10n.LocalizeActionArgument
+	// PrimaryKeys primary keys of records to localize, separated by comma or semicolon, values of composite primary keys are separated by comma and records by semicolon, e.g: "1,A; 1,B", all records in the source locale are localized if blank
+	PrimaryKeys string
+}
+
+// UnlocalizeArgument argument of unlocalize jobs
+type UnlocalizeArgument struct {
+	Locales []string
+	// PrimaryKeys primary keys of records to unlocalize, in the same format as `LocalizeArgument`'s, all records are unlocalized if blank
+	PrimaryKeys string
+}
+
+// RegisterL10nForWorker register localize and unlocalize jobs for localizable resources of Admin, records are localized one by one, with progress and a result log for each record and locale, killed jobs stop after the current record.
+// Jobs are registered automatically if the worker is added to Admin before localizable resources, as configurators of localizable resources can only find workers that have been added, call it if the worker is added later
+func RegisterL10nForWorker(Worker *worker.Worker, Admin *admin.Admin) {
+	registerLog(Worker, Admin)
+	l10n.RegisterResourceConfigurator(func(res *admin.Resource) {
+		if res.GetAdmin() == Admin {
+			registerJobs(Worker, Admin, res)
+		}
+	})
+}
+
+// registerLog register route to download result log of jobs as CSV, only once for each admin
+func registerLog(Worker *worker.Worker, Admin *admin.Admin) {
+	registerMutex.Lock()
+	defer registerMutex.Unlock()
+	if registeredLogs[Admin] {
+		return
+	}
+	registeredLogs[Admin] = true
+
+	Admin.GetRouter().Get("/l10n_job_log", func(context *admin.Context) {
+		qorJob, err := Worker.GetJob(context.Request.URL.Query().Get("job_id"))
+		if err != nil {
+			http.NotFound(context.Writer, context.Request)
+			return
+		}
+
+		if !hasLogPermission(Worker, qorJob.GetJob(), context.Context) {
+			http.Error(context.Writer, "permission denied", http.StatusForbidden)
+			return
+		}
+
+		context.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		context.Writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=l10n_job_%v.csv", qorJob.GetJobID()))
+
+		writer := csv.NewWriter(context.Writer)
+		for _, cells := range qorJob.GetResultsTable().TableCells {
+			var row []string
+			for _, cell := range cells {
+				row = append(row, cell.Value)
+			}
+			writer.Write(row)
+		}
+		writer.Flush()
+	})
+}
+
+// hasLogPermission only logs of l10n jobs are served, to users who could read the job and its resource
+func hasLogPermission(Worker *worker.Worker, job *worker.Job, context *qor.Context) bool {
+	registerMutex.Lock()
+	res, ok := registeredJobs[jobKey{Worker, job.Name}]
+	registerMutex.Unlock()
+	return ok && job.HasPermission(roles.Read, context) && res.HasPermission(roles.Read, context)
+}
+
+func registerJobs(Worker *worker.Worker, Admin *admin.Admin, res *admin.Resource) {
+	var (
+		localizeJobName   = fmt.Sprintf("Localize %v", res.Name)
+		unlocalizeJobName = fmt.Sprintf("Unlocalize %v", res.Name)
+	)
+
+	registerMutex.Lock()
+	_, registered := registeredJobs[jobKey{Worker, localizeJobName}]
+	if !registered {
+		registeredJobs[jobKey{Worker, localizeJobName}] = res
+		registeredJobs[jobKey{Worker, unlocalizeJobName}] = res
+	}
+	registerMutex.Unlock()
+	if registered {
+		return
+	}
+
+	localizeArgumentResource := Admin.NewResource(&LocalizeArgument{})
+	l10n.ConfigureLocalizeArgumentResource(localizeArgumentResource, res)
+
+	Worker.RegisterJob(&worker.Job{
+		Name:     localizeJobName,
+		Group:    "Localization",
+		Resource: localizeArgumentResource,
+		Handler: func(argument interface{}, qorJob worker.QorJobInterface) error {
+			arg := argument.(*LocalizeArgument)
+			db := l10n.WithLocalizeOptions(Admin.DB, l10n.LocalizeOptions{SkipExisting: arg.SkipExisting, Fields: arg.Fields})
+
+			mode := l10n.ModeLocale
+			if l10n.GetConfig(Admin.DB).IsGlobal(arg.From) {
+				mode = l10n.ModeGlobal
+			}
+
+			return runJob(Worker, qorJob, res, l10n.WithMode(l10n.WithLocale(db, arg.From), mode), arg.PrimaryKeys, func(record interface{}) ([]l10n.LocalizeResult, error) {
+				return l10n.Localize(db, record, arg.From, arg.To...)
+			})
+		},
+	})
+
+	unlocalizeArgumentResource := Admin.NewResource(&UnlocalizeArgument{})
+	unlocalizeArgumentResource.Meta(&admin.Meta{
+		Name: "Locales",
+		Type: "select_many",
+		Collection: func(value interface{}, context *qor.Context) (results [][]string) {
+			for _, locale := range l10n.EditableLocales(context) {
+				if !l10n.GetConfig(context.GetDB()).IsGlobal(locale) {
+					results = append(results, []string{locale, locale})
+				}
+			}
+			return
+		},
+	})
+
+	unlocalizeArgumentResource.AddValidator(func(record interface{}, metaValues *resource.MetaValues, context *qor.Context) error {
+		return l10n.ValidateEditableLocales(context, record.(*UnlocalizeArgument).Locales)
+	})
+
+	Worker.RegisterJob(&worker.Job{
+		Name:     unlocalizeJobName,
+		Group:    "Localization",
+		Resource: unlocalizeArgumentResource,
+		Handler: func(argument interface{}, qorJob worker.QorJobInterface) error {
+			arg := argument.(*UnlocalizeArgument)
+			return runJob(Worker, qorJob, res, l10n.WithMode(Admin.DB, l10n.ModeGlobal), arg.PrimaryKeys, func(record interface{}) ([]l10n.LocalizeResult, error) {
+				return l10n.Unlocalize(Admin.DB, record, arg.Locales...)
+			})
+		},
+	})
+}
+
+// runJob run fc for each record found with db, report progress and results, and stop if the job is killed
+func runJob(Worker *worker.Worker, qorJob worker.QorJobInterface, res *admin.Resource, db *gorm.DB, primaryKeys string, fc func(record interface{}) ([]l10n.LocalizeResult, error)) error {
+	var (
+		records = res.NewSlice()
+		scope   = db.NewScope(res.Value)
+		summary = l10n.LocalizeSummary{}
+	)
+
+	if keys := splitPrimaryKeys(primaryKeys, isCompositePrimaryKey(scope)); len(keys) > 0 {
+		condition, values, err := l10n.ParsePrimaryKeys(scope, keys...)
+		if err != nil {
+			return err
+		}
+		db = db.Where(condition, values...)
+	}
+
+	if err := db.Find(records).Error; err != nil {
+		return err
+	}
+
+	reflectRecords := reflect.Indirect(reflect.ValueOf(records))
+	total := reflectRecords.Len()
+	qorJob.AddLog(fmt.Sprintf("Found %v records", total))
+	qorJob.AddResultsRow(worker.TableCell{Value: "ID"}, worker.TableCell{Value: "Locale"}, worker.TableCell{Value: "Status"}, worker.TableCell{Value: "Error"})
+
+	processed, err := eachRecord(reflectRecords, func() bool { return isKilled(Worker, qorJob) }, func(i int, record interface{}) {
+		results, err := fc(record)
+		summary.Add(results...)
+
+		var primaryValues []string
+		for _, field := range db.NewScope(record).PrimaryFields() {
+			if field.Name != "LanguageCode" {
+				primaryValues = append(primaryValues, fmt.Sprint(field.Field.Interface()))
+			}
+		}
+		primaryKey := strings.Join(primaryValues, ",")
+		for _, result := range results {
+			var errMsg string
+			if result.Err != nil {
+				errMsg = result.Err.Error()
+			}
+			qorJob.AddResultsRow(worker.TableCell{Value: primaryKey}, worker.TableCell{Value: result.Locale}, worker.TableCell{Value: string(result.Status)}, worker.TableCell{Value: errMsg, Error: errMsg})
+		}
+
+		if err != nil {
+			qorJob.AddLog(fmt.Sprintf("Failed to process %v: %v", primaryKey, err))
+		}
+		qorJob.SetProgress(uint((i + 1) * 100 / total))
+	})
+
+	if err != nil {
+		qorJob.AddLog(fmt.Sprintf("Killed after %v records: %v", processed, summary))
+		return err
+	}
+
+	qorJob.AddLog(fmt.Sprintf("Done: %v, download the result log from %v/l10n_job_log?job_id=%v", summary, res.GetAdmin().GetRouter().Prefix, qorJob.GetJobID()))
+	return nil
+}
+
+var errJobKilled = errors.New("job killed")
+
+// eachRecord call fc for each record of records, stop before the next record if the job is killed, return the number of processed records
+func eachRecord(records reflect.Value, killed func() bool, fc func(i int, record interface{})) (int, error) {
+	for i := 0; i < records.Len(); i++ {
+		if killed() {
+			return i, errJobKilled
+		}
+		fc(i, records.Index(i).Interface())
+	}
+	return records.Len(), nil
+}
+
+// splitPrimaryKeys split primary keys of records, records are separated by semicolon, or by comma if there is no semicolon and the model doesn't have a composite primary key, as commas separate values of composite primary keys
+func splitPrimaryKeys(primaryKeys string, composite bool) (keys []string) {
+	separator := ";"
+	if !strings.Contains(primaryKeys, ";") && !composite {
+		separator = ","
+	}
+
+	for _, key := range strings.Split(primaryKeys, separator) {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// isCompositePrimaryKey return the model has more than one primary key besides the language code or not
+func isCompositePrimaryKey(scope *gorm.Scope) bool {
+	var count int
+	for _, field := range scope.GetModelStruct().PrimaryFields {
+		if field.Name != "LanguageCode" {
+			count++
+		}
+	}
+	return count > 1
+}
+
+// isKilled reload the job's status, to check if it has been killed from admin
+func isKilled(Worker *worker.Worker, qorJob worker.QorJobInterface) bool {
+	if job, err := Worker.GetJob(qorJob.GetJobID()); err == nil {
+		return job.GetStatus() == worker.JobStatusKilled
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/qor/admin"
+	"github.com/qor/qor"
+	"github.com/qor/qor/test/utils"
+	"github.com/qor/roles"
+	"github.com/qor/worker"
+)
+
+type Article struct {
+	ID    uint `gorm:"primary_key"`
+	Title string
+}
+
+func TestSplitPrimaryKeys(t *testing.T) {
+	tests := []struct {
+		primaryKeys string
+		composite   bool
+		keys        []string
+	}{
+		{"", false, nil},
+		{" 1, 2 ,,3 ", false, []string{"1", "2", "3"}},
+		{"1; 2;", false, []string{"1", "2"}},
+		{"1,A", true, []string{"1,A"}},
+		{"1,A; 1,B", true, []string{"1,A", "1,B"}},
+		{"1,A; 2,B", false, []string{"1,A", "2,B"}},
+	}
+
+	for _, test := range tests {
+		if keys := splitPrimaryKeys(test.primaryKeys, test.composite); !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("primary keys %q (composite: %v) should be split into %#v, but got %#v", test.primaryKeys, test.composite, test.keys, keys)
+		}
+	}
+}
+
+func TestEachRecordStopWhenKilled(t *testing.T) {
+	var (
+		records   = reflect.ValueOf([]string{"1", "2", "3"})
+		processed []interface{}
+	)
+
+	count, err := eachRecord(records, func() bool { return len(processed) == 2 }, func(i int, record interface{}) {
+		processed = append(processed, record)
+	})
+
+	if err != errJobKilled || count != 2 || !reflect.DeepEqual(processed, []interface{}{"1", "2"}) {
+		t.Errorf("should stop after the current record when killed, but processed %v records %#v, error: %v", count, processed, err)
+	}
+
+	processed = nil
+	if count, err = eachRecord(records, func() bool { return false }, func(i int, record interface{}) {
+		processed = append(processed, record)
+	}); err != nil || count != 3 || len(processed) != 3 {
+		t.Errorf("should process all records, but processed %v records, error: %v", count, err)
+	}
+}
+
+func TestLogPermission(t *testing.T) {
+	var (
+		Worker = worker.New()
+		Admin  = admin.New(&qor.Config{DB: utils.TestDB()})
+		res    = Admin.NewResource(&Article{}, &admin.Config{Permission: roles.Deny(roles.Read, "guest")})
+		job    = &worker.Job{Name: "Localize Articles", Permission: roles.Deny(roles.Read, "visitor")}
+	)
+
+	if hasLogPermission(Worker, job, &qor.Context{}) {
+		t.Errorf("logs of jobs that aren't l10n jobs should not be served")
+	}
+
+	registerMutex.Lock()
+	registeredJobs[jobKey{Worker, job.Name}] = res
+	registerMutex.Unlock()
+
+	if !hasLogPermission(Worker, job, &qor.Context{Roles: []string{"translator"}}) {
+		t.Errorf("users who could read the job and its resource should download its log")
+	}
+
+	if hasLogPermission(Worker, job, &qor.Context{Roles: []string{"visitor"}}) {
+		t.Errorf("users who couldn't read the job should not download its log")
+	}
+
+	if hasLogPermission(Worker, job, &qor.Context{Roles: []string{"guest"}}) {
+		t.Errorf("users who couldn't read the job's resource should not download its log")
+	}
+
+	if hasLogPermission(worker.New(), job, &qor.Context{}) {
+		t.Errorf("logs should only be served by the worker that registered the job")
+	}
+}
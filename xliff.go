@@ -0,0 +1,189 @@
+package l10n
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/jinzhu/gorm"
+)
+
+// XLIFF versions
+const (
+	XLIFF12 = "1.2"
+	XLIFF20 = "2.0"
+)
+
+type xliff12 struct {
+	XMLName xml.Name      `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string        `xml:"version,attr"`
+	Files   []xliff12File `xml:"file"`
+}
+
+type xliff12File struct {
+	Original       string        `xml:"original,attr"`
+	SourceLanguage string        `xml:"source-language,attr"`
+	TargetLanguage string        `xml:"target-language,attr"`
+	Datatype       string        `xml:"datatype,attr"`
+	Units          []xliff12Unit `xml:"body>trans-unit"`
+}
+
+type xliff12Unit struct {
+	ID      string        `xml:"id,attr"`
+	Resname string        `xml:"resname,attr,omitempty"`
+	Source  string        `xml:"source"`
+	Target  xliff12Target `xml:"target"`
+}
+
+type xliff12Target struct {
+	State string `xml:"state,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xliff20 struct {
+	XMLName xml.Name      `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string        `xml:"version,attr"`
+	SrcLang string        `xml:"srcLang,attr"`
+	TrgLang string        `xml:"trgLang,attr"`
+	Files   []xliff20File `xml:"file"`
+}
+
+type xliff20File struct {
+	ID    string        `xml:"id,attr"`
+	Units []xliff20Unit `xml:"unit"`
+}
+
+type xliff20Unit struct {
+	ID      string         `xml:"id,attr"`
+	Name    string         `xml:"name,attr"`
+	Segment xliff20Segment `xml:"segment"`
+}
+
+type xliff20Segment struct {
+	State  string `xml:"state,attr,omitempty"`
+	Source string `xml:"source"`
+	Target string `xml:"target"`
+}
+
+// ExportXLIFF export translatable fields of localizable models into XLIFF with version 1.2 or 2.0, global values as sources, and values in locale as targets
+func ExportXLIFF(db *gorm.DB, w io.Writer, version string, locale string, models ...interface{}) error {
+	var document interface{}
+
+	switch version {
+	case XLIFF12:
+		doc := xliff12{Version: XLIFF12}
+		for _, model := range models {
+			units, err := ExportTranslationUnits(db, model, locale)
+			if err != nil {
+				return err
+			}
+
+			file := xliff12File{Original: db.NewScope(model).TableName(), SourceLanguage: GetConfig(db).GlobalLocale, TargetLanguage: locale, Datatype: "plaintext"}
+			for _, unit := range units {
+				xliffUnit := xliff12Unit{ID: unit.Key, Source: unit.Source, Target: xliff12Target{Value: unit.Target, State: "translated"}}
+				if unit.Target == "" {
+					xliffUnit.Target.State = "needs-translation"
+				}
+				file.Units = append(file.Units, xliffUnit)
+			}
+			doc.Files = append(doc.Files, file)
+		}
+		document = doc
+	case XLIFF20:
+		doc := xliff20{Version: XLIFF20, SrcLang: GetConfig(db).GlobalLocale, TrgLang: locale}
+		for _, model := range models {
+			units, err := ExportTranslationUnits(db, model, locale)
+			if err != nil {
+				return err
+			}
+
+			// ids are NMTOKEN in XLIFF 2.0, so keep unit keys in names
+			file := xliff20File{ID: fmt.Sprintf("f%v", len(doc.Files)+1)}
+			for _, unit := range units {
+				xliffUnit := xliff20Unit{ID: fmt.Sprintf("u%v", len(file.Units)+1), Name: unit.Key, Segment: xliff20Segment{Source: unit.Source, Target: unit.Target, State: "translated"}}
+				if unit.Target == "" {
+					xliffUnit.Segment.State = "initial"
+				}
+				file.Units = append(file.Units, xliffUnit)
+			}
+			doc.Files = append(doc.Files, file)
+		}
+		document = doc
+	default:
+		return fmt.Errorf("unsupported XLIFF version %v", version)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
+
+// ImportXLIFF import targets from XLIFF 1.2 or 2.0 into the target language of the document, records are saved through l10n callbacks
+func ImportXLIFF(db *gorm.DB, r io.Reader, models ...interface{}) (result ImportResult, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return result, err
+	}
+
+	var header struct {
+		Version string `xml:"version,attr"`
+	}
+
+	if err = xml.Unmarshal(content, &header); err != nil {
+		return result, err
+	}
+
+	var (
+		locale string
+		units  []TranslationUnit
+	)
+
+	switch header.Version {
+	case XLIFF12:
+		var doc xliff12
+		if err = xml.Unmarshal(content, &doc); err != nil {
+			return result, err
+		}
+
+		for _, file := range doc.Files {
+			if locale == "" {
+				locale = file.TargetLanguage
+			} else if locale != file.TargetLanguage {
+				return result, fmt.Errorf("inconsistent target languages %v, %v", locale, file.TargetLanguage)
+			}
+
+			for _, unit := range file.Units {
+				key := unit.ID
+				if unit.Resname != "" {
+					key = unit.Resname
+				}
+				units = append(units, TranslationUnit{Key: key, Source: unit.Source, Target: unit.Target.Value})
+			}
+		}
+	case XLIFF20:
+		var doc xliff20
+		if err = xml.Unmarshal(content, &doc); err != nil {
+			return result, err
+		}
+
+		locale = doc.TrgLang
+		for _, file := range doc.Files {
+			for _, unit := range file.Units {
+				units = append(units, TranslationUnit{Key: unit.Name, Source: unit.Segment.Source, Target: unit.Segment.Target})
+			}
+		}
+	default:
+		return result, fmt.Errorf("unsupported XLIFF version %v", header.Version)
+	}
+
+	if locale == "" {
+		return result, fmt.Errorf("target language is required")
+	}
+
+	return ImportTranslationUnits(db, locale, units, models...), nil
+}
@@ -0,0 +1,219 @@
+package l10n
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+var xlsxFiles = map[string]string{
+	"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`,
+	"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`,
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Translations" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+}
+
+var xlsxFileNames = []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}
+
+type xlsxSheet struct {
+	Rows []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	Ref   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref       string       `xml:"r,attr"`
+	Type      string       `xml:"t,attr"`
+	Value     string       `xml:"v"`
+	InlineStr *xlsxRichStr `xml:"is"`
+}
+
+type xlsxRichStr struct {
+	Text string        `xml:"t"`
+	Runs []xlsxRichStr `xml:"r"`
+}
+
+func (str xlsxRichStr) String() string {
+	value := str.Text
+	for _, run := range str.Runs {
+		value += run.String()
+	}
+	return value
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichStr `xml:"si"`
+}
+
+// xlsxColumnName convert column index to name, e.g: 0 => A, 26 => AA
+func xlsxColumnName(idx int) (name string) {
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return
+}
+
+// xlsxColumnIndex convert cell reference to column index, e.g: AA3 => 26
+func xlsxColumnIndex(ref string) int {
+	var idx int
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		idx = idx*26 + int(r-'A'+1)
+	}
+	return idx - 1
+}
+
+func writeXLSX(w io.Writer, rows [][]string) error {
+	archive := zip.NewWriter(w)
+	for _, name := range xlsxFileNames {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, xlsxFiles[name]); err != nil {
+			return err
+		}
+	}
+
+	var sheet bytes.Buffer
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for rowIdx, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%v">`, rowIdx+1)
+		for idx, value := range row {
+			fmt.Fprintf(&sheet, `<c r="%v%v" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(idx), rowIdx+1)
+			if err := xml.EscapeText(&sheet, []byte(value)); err != nil {
+				return err
+			}
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := sheet.WriteTo(file); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// readXLSX read rows of the first worksheet, supports both inline and shared strings, so sheets saved by spreadsheet applications could be imported
+func readXLSX(r io.Reader) (rows [][]string, err error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sheet         xlsxSheet
+		sharedStrings xlsxSharedStrings
+		hasSheet      bool
+	)
+
+	for _, file := range archive.File {
+		var value interface{}
+		switch file.Name {
+		case "xl/worksheets/sheet1.xml":
+			value, hasSheet = &sheet, true
+		case "xl/sharedStrings.xml":
+			value = &sharedStrings
+		default:
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = xml.NewDecoder(reader).Decode(value)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasSheet {
+		return nil, errors.New("worksheet not found")
+	}
+
+	for _, xlsxRow := range sheet.Rows {
+		var row []string
+		for idx, cell := range xlsxRow.Cells {
+			if cell.Ref != "" {
+				idx = xlsxColumnIndex(cell.Ref)
+			}
+
+			var value string
+			switch cell.Type {
+			case "inlineStr":
+				if cell.InlineStr != nil {
+					value = cell.InlineStr.String()
+				}
+			case "s":
+				index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("invalid shared string %v", cell.Value)
+				}
+				value = sharedStrings.Items[index].String()
+			default:
+				value = cell.Value
+			}
+
+			for len(row) <= idx {
+				row = append(row, "")
+			}
+			row[idx] = value
+		}
+
+		// spreadsheet applications omit empty rows, place rows by their numbers, so errors are reported with right row numbers
+		for len(rows) < xlsxRow.Ref-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ExportXLSX export a translation spreadsheet of model as XLSX, it has the same columns with ExportCSV
+func ExportXLSX(db *gorm.DB, w io.Writer, model interface{}, locale string) error {
+	rows, err := spreadsheetRows(db, model, locale)
+	if err != nil {
+		return err
+	}
+	return writeXLSX(w, rows)
+}
+
+// ImportXLSX import translations from the first worksheet of a XLSX spreadsheet exported by ExportXLSX, and report the changes and errors
+func ImportXLSX(db *gorm.DB, r io.Reader, model interface{}, options SpreadsheetImportOptions) (SpreadsheetReport, error) {
+	rows, err := readXLSX(r)
+	if err != nil {
+		return SpreadsheetReport{}, err
+	}
+	return importSpreadsheetRows(db, model, rows, options)
+}